@@ -276,6 +276,7 @@ func main() {
 		Clusters: clusters,
 		// Strategy: &core.RoundRobin{},
 		Strategy: &core.CIawareStrategy{},
+		Clock:    core.WallClock{},
 	}
 
 	http.HandleFunc("/workload-ingest", handleWorkloadIngest)
@@ -0,0 +1,152 @@
+// Command calibrate fits per-host power models to Scaphandre metrics exported
+// by the central unit and emits them as the power_model column of a
+// run_sim nodes CSV:
+//
+//	calibrate -in /data/metrics_aggregated.csv -nodes config/nodes.csv -out config/nodes_calibrated.csv
+//
+// Without -nodes it writes one row per host (host,samples,idle_w,peak_w,
+// r2,rmse_w,power_model).
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"kube-scheduler/pkg/calibrate"
+)
+
+func main() {
+	var inFlag, nodesCSV, outPath, model string
+	var o calibrate.Options
+	var bins int
+
+	flag.StringVar(&inFlag, "in", "/data/metrics_aggregated.csv", "comma-separated metric CSVs (metrics_aggregated.csv, full_scaphandre_metrics.csv)")
+	flag.StringVar(&o.PowerMetric, "power-metric", calibrate.DefaultPowerMetric, "host power series (*_microwatts are converted to W)")
+	flag.StringVar(&o.UtilMetric, "util-metric", calibrate.DefaultUtilMetric, "CPU utilisation series")
+	flag.Float64Var(&o.UtilScale, "util-scale", 0, "divide utilisation by this, e.g. the core count for scaph_host_load_avg_one (0 = percent if any value > 1)")
+	flag.StringVar(&o.Host, "host", "", "host of series without a node/host/instance label (default: the CSV's base name)")
+	flag.StringVar(&model, "model", "linear", "fitted model: linear|piecewise")
+	flag.IntVar(&bins, "bins", 10, "utilisation bins of the piecewise model")
+	flag.StringVar(&nodesCSV, "nodes", "", "nodes CSV whose power_model (and peak_power_w) to fill for hosts matching a node name")
+	flag.StringVar(&outPath, "out", "", "output CSV (default stdout)")
+	flag.Parse()
+
+	if model != "linear" && model != "piecewise" {
+		log.Fatalf("unknown -model %q (want linear or piecewise)", model)
+	}
+	if model == "linear" {
+		bins = 0
+	}
+
+	var samples []calibrate.Sample
+	for _, p := range strings.Split(inFlag, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		fo := o
+		if fo.Host == "" {
+			fo.Host = strings.TrimSuffix(filepath.Base(p), filepath.Ext(p))
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			log.Fatalf("open %s: %v", p, err)
+		}
+		s, err := calibrate.Read(f, fo)
+		f.Close()
+		if err != nil {
+			log.Fatalf("%s: %v", p, err)
+		}
+		log.Printf("%s: %d samples", p, len(s))
+		samples = append(samples, s...)
+	}
+	fits := calibrate.FitAll(samples, bins)
+	for _, f := range fits {
+		log.Printf("%s: n=%d idle=%.1fW peak=%.1fW R²=%.3f RMSE=%.1fW", f.Host, f.N, f.Linear.IdleW, f.Linear.PeakW, f.R2, f.RMSE)
+	}
+
+	var out io.Writer = os.Stdout
+	if outPath != "" {
+		f, err := os.Create(outPath)
+		if err != nil {
+			log.Fatalf("create %s: %v", outPath, err)
+		}
+		defer f.Close()
+		out = f
+	}
+	w := csv.NewWriter(out)
+	defer w.Flush()
+
+	if nodesCSV == "" {
+		w.Write([]string{"host", "samples", "idle_w", "peak_w", "r2", "rmse_w", "power_model"})
+		for _, f := range fits {
+			w.Write([]string{
+				f.Host, fmt.Sprint(f.N),
+				fmt.Sprintf("%.1f", f.Linear.IdleW), fmt.Sprintf("%.1f", f.Linear.PeakW),
+				fmt.Sprintf("%.4f", f.R2), fmt.Sprintf("%.2f", f.RMSE),
+				fmt.Sprint(f.Model()),
+			})
+		}
+		return
+	}
+
+	byHost := map[string]calibrate.Fit{}
+	for _, f := range fits {
+		byHost[f.Host] = f
+	}
+	if err := annotateNodes(w, nodesCSV, byHost); err != nil {
+		log.Fatalf("%s: %v", nodesCSV, err)
+	}
+}
+
+// annotateNodes copies the nodes CSV to w, filling peak_power_w and
+// power_model of every node named after a fitted host. Missing optional
+// columns are added empty, which the loader reads as unset.
+func annotateNodes(w *csv.Writer, path string, fits map[string]calibrate.Fit) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return fmt.Errorf("empty file")
+	}
+	const (
+		peakCol  = 5 // name,cpu,mem,ci_profile,site_id,peak_power_w,power_model
+		modelCol = 6
+	)
+	header := rows[0]
+	for len(header) <= modelCol {
+		header = append(header, []string{"name", "cpu", "mem", "ci_profile", "site_id", "peak_power_w", "power_model"}[len(header)])
+	}
+	w.Write(header)
+
+	matched := map[string]bool{}
+	for _, rec := range rows[1:] {
+		for len(rec) < len(header) {
+			rec = append(rec, "")
+		}
+		if fit, ok := fits[rec[0]]; ok {
+			rec[peakCol] = fmt.Sprintf("%.1f", fit.Model().Power(1))
+			rec[modelCol] = fmt.Sprint(fit.Model())
+			matched[rec[0]] = true
+		}
+		w.Write(rec)
+	}
+	for host := range fits {
+		if !matched[host] {
+			log.Printf("host %s matches no node in %s", host, path)
+		}
+	}
+	return w.Error()
+}
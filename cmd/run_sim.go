@@ -1,23 +1,36 @@
 package main
 
 import (
+	"context"
 	"encoding/csv"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
-	"kube-scheduler/models/carbonscaler"
-	"kube-scheduler/models/cisched"
-	"kube-scheduler/models/k8sched"
 	"kube-scheduler/pkg/core"
+	"kube-scheduler/pkg/experiment"
+	"kube-scheduler/pkg/forecast"
 	"kube-scheduler/pkg/generator"
 	"kube-scheduler/pkg/loader"
 	"kube-scheduler/pkg/metrics"
+	"kube-scheduler/pkg/predict"
+
+	// registered schedulers (see -list-schedulers)
+	_ "kube-scheduler/models/carbonscaler"
+	_ "kube-scheduler/models/cisched"
+	_ "kube-scheduler/models/ecovisor"
+	_ "kube-scheduler/models/energyvis"
+	_ "kube-scheduler/models/greenalg"
+	_ "kube-scheduler/models/k8sched"
+	_ "kube-scheduler/models/site"
 )
 
 // parseFloatSlice converts a comma-separated list of floats into a slice
@@ -48,75 +61,427 @@ func parseIntSlice(s string) []int {
 	return out
 }
 
-// helper: parse seconds → []time.Duration
-func parseDurationSliceSeconds(s string) []time.Duration {
-    if strings.TrimSpace(s) == "" { return nil }
-    floats := parseFloatSlice(s)
-    out := make([]time.Duration, len(floats))
-    for i, v := range floats {
-        out[i] = time.Duration(v * float64(time.Second))
-    }
-    return out
+// parsePolicyParams splits "name:k=v,k=v;name:k=v" into per-scheduler
+// parameter maps
+func parsePolicyParams(s string) (map[string]map[string]any, error) {
+	out := map[string]map[string]any{}
+	for _, part := range strings.Split(s, ";") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		name, kvs, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("expected <scheduler>:<key>=<value>,..., got %q", part)
+		}
+		name = strings.TrimSpace(name)
+		if out[name] == nil {
+			out[name] = map[string]any{}
+		}
+		for _, kv := range strings.Split(kvs, ",") {
+			if kv = strings.TrimSpace(kv); kv == "" {
+				continue
+			}
+			k, v, ok := strings.Cut(kv, "=")
+			if !ok {
+				return nil, fmt.Errorf("%s: expected key=value, got %q", name, kv)
+			}
+			out[name][strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	return out, nil
 }
 
 func main() {
+	var specPath string
 	var nodesCSV, wlCSV, ciWeightsFlag, batchSizesFlag string
 	var durScale float64
 	var durationsFlag string
-
+	var sitesCSV, ciTracesFlag, priceTracesFlag, renewableTracesFlag, pueTracesFlag, tempTracesFlag, powerCurvesCSV string
+	var seed int64
+	var seedsFlag string
+	var reps int
+	var forecasterFlag, predictorFlag, emissionsFlag string
+	var deadlineSlack float64
+	var wlStartFlag string
+	var shift bool
+	var shiftStep time.Duration
+	var schedulersFlag, policyParamsFlag string
+	var listSchedulers bool
+	var workers int
+	var metricsFlag, utilStepFlag, queuesFlag string
+	var prioAge, prioFairShare, prioClass float64
+	var prioMaxAge, fsHalfLife time.Duration
+	var sharesFlag string
+	var preemptPriority bool
+	var preemptCI float64
+	var preemptCheck, preemptHold, preemptMinRun, ckptOverhead, ckptInterval time.Duration
+
+	flag.StringVar(&specPath, "spec", "", "JSON experiment spec; when set, the sweep flags below are ignored")
 	flag.StringVar(&nodesCSV, "nodes-csv", "", "path to nodes CSV (auto-generate if empty)")
 	flag.StringVar(&wlCSV, "wl-csv", "", "path to workloads CSV (auto-generate if empty)")
 	flag.StringVar(&ciWeightsFlag, "ci-weights", "0.1,0.5,1.0,1.5", "comma-separated CI-base weights")
 	flag.StringVar(&batchSizesFlag, "batch-sizes", "50,100,200", "comma-separated batch sizes")
+	flag.StringVar(&sitesCSV, "sites-csv", "config/sites.csv", "path to sites CSV (site_id,pue,k,ci_region[,tariff,renewable,renewable_kw,battery_kwh,battery_kw,battery_eff,battery_soc,pue_model])")
+	flag.Int64Var(&seed, "seed", 1, "seed for generated workloads and stochastic CI profiles (randwalk, ou)")
+	flag.StringVar(&seedsFlag, "seeds", "", "comma-separated seeds to repeat the sweep over (overrides -seed)")
+	flag.IntVar(&reps, "reps", 1, "repetitions per seed (each with a derived seed)")
+	flag.StringVar(&forecasterFlag, "forecaster", "", "CI forecaster for carbon-aware policies: persistence|seasonal[:h]|ewma[:alpha]|dayahead[:sigma]|oracle (empty = instantaneous CI)")
+	flag.StringVar(&priceTracesFlag, "price-traces", "", "comma-separated electricity price trace CSVs (€/kWh or €/MWh), matched to sites by tariff or ci_region")
+	flag.StringVar(&renewableTracesFlag, "renewable-traces", "", "comma-separated on-site generation trace CSVs (kW or MW), matched to sites by their renewable column")
+	flag.StringVar(&pueTracesFlag, "pue-traces", "", "comma-separated PUE trace CSVs, used by sites with pue_model trace:<region>")
+	flag.StringVar(&tempTracesFlag, "temp-traces", "", "comma-separated outside-temperature trace CSVs (°C or K), used by sites with pue_model temp:<region>:…")
+	flag.StringVar(&powerCurvesCSV, "power-curves", "", "per-node-type power curves CSV (model,util,watts), referenced by the nodes CSV power_model column")
+	flag.StringVar(&emissionsFlag, "emissions", "average", "CI used by policies and co2_g: average|marginal (marginal columns of -ci-traces; both are reported)")
+	flag.StringVar(&predictorFlag, "predictor", "", "job runtime predictor policies see instead of the true duration: oracle|noisy[:sigma]|tag_mean|quantile[:q]|regression[:lambda] (empty = true durations)")
+	flag.StringVar(&ciTracesFlag, "ci-traces", "", "comma-separated CI trace CSVs (ElectricityMaps/WattTime export), matched to sites by ci_region")
+
+	flag.StringVar(&schedulersFlag, "schedulers", "carbonscaler,ci_aware,site,k8,greenalg,ecovisor,energyvis", "comma-separated registered schedulers to sweep (see -list-schedulers)")
+	flag.StringVar(&policyParamsFlag, "policy-params", "", "per-scheduler parameters, e.g. \"ci_aware:wait=0.3,util=0.1;carbonscaler:shift_step=30m\"")
+	flag.StringVar(&metricsFlag, "metrics", "", "comma-separated summary columns (empty = all)")
+	flag.StringVar(&utilStepFlag, "util-step", "", "write each run's CPU utilisation over time at this resolution, e.g. 5m")
+	flag.StringVar(&queuesFlag, "queues", "first_fit", "comma-separated queue disciplines to run every scheduler under: first_fit|fcfs|easy|conservative (backfilling uses -predictor estimates)")
+	flag.Float64Var(&prioAge, "prio-age", 0, "queue priority weight of the time queued (multifactor; all weights 0 = submission order)")
+	flag.Float64Var(&prioFairShare, "prio-fairshare", 0, "queue priority weight of the tenant's fair-share factor (tenant label, else tag)")
+	flag.Float64Var(&prioClass, "prio-class", 0, "queue priority weight of the job's priority label")
+	flag.DurationVar(&prioMaxAge, "prio-max-age", core.DefaultPriorityMaxAge, "queue time at which the age factor saturates")
+	flag.DurationVar(&fsHalfLife, "fairshare-half-life", core.DefaultUsageHalfLife, "half-life of the tenants' decayed usage")
+	flag.StringVar(&sharesFlag, "shares", "", "tenant fair-share targets, e.g. \"A=2,B=1\" (others get 1)")
+	flag.BoolVar(&preemptPriority, "preempt", false, "let jobs that do not fit evict running preemptible jobs of lower priority (workload labels preemptible, priority)")
+	flag.Float64Var(&preemptCI, "preempt-ci", 0, "suspend preemptible jobs while their node's CI is above this (gCO2/kWh; 0 = off)")
+	flag.DurationVar(&preemptCheck, "preempt-check", core.DefaultPreemptCheck, "how often -preempt-ci re-checks node CI")
+	flag.DurationVar(&preemptHold, "preempt-hold", 0, "how long a job suspended by -preempt-ci waits before it may resume (0 = -preempt-check)")
+	flag.DurationVar(&preemptMinRun, "preempt-min-run", 0, "how long a job segment runs past its restore before -preempt-ci may suspend it (0 = -preempt-check; at least -checkpoint-interval)")
+	flag.DurationVar(&ckptOverhead, "checkpoint-overhead", 0, "restore time added to every resumed job segment")
+	flag.DurationVar(&ckptInterval, "checkpoint-interval", 0, "periodic checkpoint interval; work since the last checkpoint is lost on suspension (0 = checkpoint on suspension)")
+	flag.IntVar(&workers, "workers", runtime.NumCPU(), "runs executed in parallel")
+	flag.BoolVar(&listSchedulers, "list-schedulers", false, "print the registered schedulers and their parameters, then exit")
 
 	// NEW knobs
 	flag.Float64Var(&durScale, "dur-scale", 1.0, "multiply all job durations by this factor")
 	flag.StringVar(&durationsFlag, "durations", "", "comma-separated job durations (seconds) to override, assigned round-robin")
 
+	// Temporal shifting
+	flag.StringVar(&wlStartFlag, "wl-start", "", "RFC 3339 first submission of generated workloads (default "+generator.DefaultEpoch.Format(time.RFC3339)+")")
+	flag.Float64Var(&deadlineSlack, "deadline-slack", 0, "give jobs without a deadline one of duration*(1+slack) after submit (0 = none)")
+	flag.BoolVar(&shift, "shift", false, "also run carbonscaler with temporal shifting (carbonscaler_shift)")
+	flag.DurationVar(&shiftStep, "shift-step", 15*time.Minute, "spacing of candidate start times for shifting")
+
 	flag.Parse()
 
-	// Auto-generate node and workload CSVs if not provided
+	if listSchedulers {
+		for _, name := range core.PolicyNames() {
+			f, _ := core.LookupPolicy(name)
+			fmt.Print(f.Usage())
+		}
+		return
+	}
+
+	// The sweep is always driven by a spec: loaded from -spec, or built from
+	// the flags so that flag-driven runs are just as reproducible
+	var spec *experiment.Spec
+	var rawSpec []byte
+	if specPath != "" {
+		var err error
+		spec, rawSpec, err = experiment.Load(specPath)
+		if err != nil {
+			log.Fatalf("invalid -spec: %v", err)
+		}
+	} else {
+		overrides, err := parsePolicyParams(policyParamsFlag)
+		if err != nil {
+			log.Fatalf("invalid -policy-params: %v", err)
+		}
+		spec = &experiment.Spec{
+			Inputs:      experiment.Inputs{Nodes: nodesCSV, Workloads: wlCSV, Sites: sitesCSV, PowerCurves: powerCurvesCSV},
+			Workload:    experiment.Workload{DurScale: durScale, DeadlineSlack: deadlineSlack, Start: wlStartFlag},
+			Forecaster:  forecasterFlag,
+			Predictor:   predictorFlag,
+			Emissions:   emissionsFlag,
+			CIWeights:   parseFloatSlice(ciWeightsFlag),
+			BatchSizes:  parseIntSlice(batchSizesFlag),
+			Seeds:       []int64{seed},
+			Repetitions: reps,
+			UtilStep:    utilStepFlag,
+		}
+		for _, q := range strings.Split(queuesFlag, ",") {
+			if q = strings.TrimSpace(q); q != "" {
+				spec.Queues = append(spec.Queues, q)
+			}
+		}
+		if prioAge != 0 || prioFairShare != 0 || prioClass != 0 {
+			spec.Priority = &experiment.Priority{
+				Age:       prioAge,
+				FairShare: prioFairShare,
+				Class:     prioClass,
+				MaxAge:    prioMaxAge.String(),
+				HalfLife:  fsHalfLife.String(),
+			}
+			for _, kv := range strings.Split(sharesFlag, ",") {
+				if kv = strings.TrimSpace(kv); kv == "" {
+					continue
+				}
+				k, v, _ := strings.Cut(kv, "=")
+				share, err := strconv.ParseFloat(v, 64)
+				if err != nil {
+					log.Fatalf("invalid -shares %q: %v", kv, err)
+				}
+				if spec.Priority.Shares == nil {
+					spec.Priority.Shares = map[string]float64{}
+				}
+				spec.Priority.Shares[strings.TrimSpace(k)] = share
+			}
+		}
+		if preemptPriority || preemptCI > 0 {
+			spec.Preemption = &experiment.Preemption{
+				Priority:           preemptPriority,
+				CIThreshold:        preemptCI,
+				Check:              preemptCheck.String(),
+				Hold:               preemptHold.String(),
+				MinRun:             preemptMinRun.String(),
+				CheckpointOverhead: ckptOverhead.String(),
+				CheckpointInterval: ckptInterval.String(),
+			}
+		}
+		for _, p := range strings.Split(ciTracesFlag, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				spec.Inputs.CITraces = append(spec.Inputs.CITraces, p)
+			}
+		}
+		for _, p := range strings.Split(priceTracesFlag, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				spec.Inputs.PriceTraces = append(spec.Inputs.PriceTraces, p)
+			}
+		}
+		for _, p := range strings.Split(renewableTracesFlag, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				spec.Inputs.RenewableTraces = append(spec.Inputs.RenewableTraces, p)
+			}
+		}
+		for _, p := range strings.Split(pueTracesFlag, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				spec.Inputs.PUETraces = append(spec.Inputs.PUETraces, p)
+			}
+		}
+		for _, p := range strings.Split(tempTracesFlag, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				spec.Inputs.TempTraces = append(spec.Inputs.TempTraces, p)
+			}
+		}
+		for _, m := range strings.Split(metricsFlag, ",") {
+			if m = strings.TrimSpace(m); m != "" {
+				spec.Metrics = append(spec.Metrics, m)
+			}
+		}
+		if strings.TrimSpace(seedsFlag) != "" {
+			spec.Seeds = nil
+			for _, v := range parseIntSlice(seedsFlag) {
+				spec.Seeds = append(spec.Seeds, int64(v))
+			}
+		}
+		if strings.TrimSpace(durationsFlag) != "" {
+			spec.Workload.DurationsS = parseFloatSlice(durationsFlag)
+		}
+		names := strings.Split(schedulersFlag, ",")
+		if shift {
+			names = append(names, "carbonscaler_shift")
+		}
+		seen := map[string]bool{}
+		for _, name := range names {
+			if name = strings.TrimSpace(name); name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			params := overrides[name]
+			if f, ok := core.LookupPolicy(name); ok {
+				if _, ok := f.Param("shift_step"); ok && params["shift_step"] == nil {
+					if params == nil {
+						params = map[string]any{}
+					}
+					params["shift_step"] = shiftStep.String()
+				}
+			}
+			spec.Policies = append(spec.Policies, experiment.Policy{Name: name, Params: params})
+		}
+		for name := range overrides {
+			if !seen[name] {
+				log.Printf("warning: -policy-params for %q, which is not in -schedulers", name)
+			}
+		}
+		spec.Defaults()
+	}
+
+	variants, err := spec.Variants()
+	if err != nil {
+		log.Fatalf("invalid experiment: %v", err)
+	}
+	columns, err := selectMetrics(spec.Metrics)
+	if err != nil {
+		log.Fatalf("invalid experiment: %v", err)
+	}
+	var utilStep time.Duration
+	if spec.UtilStep != "" {
+		if utilStep, err = time.ParseDuration(spec.UtilStep); err != nil {
+			log.Fatalf("invalid experiment: util_step: %v", err)
+		}
+	}
+	if spec.Emissions != "" && spec.Emissions != "average" && spec.Emissions != "marginal" {
+		log.Fatalf("invalid experiment: emissions %q (want average or marginal)", spec.Emissions)
+	}
+	wlStart := generator.DefaultEpoch
+	if spec.Workload.Start != "" {
+		if wlStart, err = time.Parse(time.RFC3339, spec.Workload.Start); err != nil {
+			log.Fatalf("invalid experiment: workload start: %v", err)
+		}
+	}
+	var prio *core.Multifactor
+	var shares map[string]float64
+	if spec.Priority != nil {
+		if prio, err = spec.Priority.Build(); err != nil {
+			log.Fatalf("invalid experiment: %v", err)
+		}
+		shares = spec.Priority.Shares
+	}
+	var preempt *core.Preemption
+	if spec.Preemption != nil {
+		if preempt, err = spec.Preemption.Build(); err != nil {
+			log.Fatalf("invalid experiment: %v", err)
+		}
+		preempt.CIAt = metrics.CIAt
+	}
+
+	// Auto-generate the node CSV if not provided (workloads are generated per seed)
+	nodesCSV = spec.Inputs.Nodes
 	if nodesCSV == "" {
 		nodesCSV = "config/nodes.csv"
 		if err := generator.GenerateNodes(nodesCSV); err != nil {
 			log.Fatalf("node generation failed: %v", err)
 		}
 	}
-	if wlCSV == "" {
-		wlCSV = "config/workloads.csv"
-		if err := generator.GenerateWorkloads(wlCSV, time.Now().Unix()); err != nil {
-			log.Fatalf("workload generation failed: %v", err)
-		}
+	sitesCSV = spec.Inputs.Sites
+	if sitesCSV == "" {
+		sitesCSV = "config/sites.csv"
 	}
 
-	ciWeights := parseFloatSlice(ciWeightsFlag)
-	batchSizes := parseIntSlice(batchSizesFlag)
+	// Nodes, sites and CI traces are read once; every run gets its own
+	// clone of the nodes (shared sites and traces are read-only)
+	traces, marginal := map[string]*core.Trace{}, map[string]*core.Trace{}
+	for _, p := range spec.Inputs.CITraces {
+		for region, tr := range loader.LoadCITracesFromCSV(p) {
+			traces[region] = tr
+		}
+		for region, tr := range loader.LoadMarginalCITracesFromCSV(p) {
+			marginal[region] = tr
+		}
+	}
+	baseNodes := loader.LoadNodesFromCSV(nodesCSV)
+	var curves map[string]core.PowerModel
+	if spec.Inputs.PowerCurves != "" {
+		curves = loader.LoadPowerCurvesFromCSV(spec.Inputs.PowerCurves)
+	}
+	loader.AttachPowerModels(baseNodes, curves)
+	caps := map[string]float64{}
+	for _, n := range baseNodes {
+		caps[n.Name] = n.TotalCPU
+	}
+	sites := loader.LoadSitesFromCSV(sitesCSV)
+	loader.AttachCITraces(sites, traces)
+	loader.AttachMarginalCITraces(sites, marginal)
+	if spec.Emissions == "marginal" {
+		if len(marginal) == 0 {
+			log.Printf("warning: marginal emissions requested but no CI trace has a marginal column; using average CI")
+		}
+		for _, s := range sites {
+			s.Marginal = true
+		}
+	}
+	prices := map[string]*core.Trace{}
+	for _, p := range spec.Inputs.PriceTraces {
+		for region, tr := range loader.LoadPriceTracesFromCSV(p) {
+			prices[region] = tr
+		}
+	}
+	loader.AttachPrices(sites, prices)
+	renewables := map[string]*core.Trace{}
+	for _, p := range spec.Inputs.RenewableTraces {
+		for region, tr := range loader.LoadRenewableTracesFromCSV(p) {
+			renewables[region] = tr
+		}
+	}
+	loader.AttachRenewables(sites, renewables)
+	pueTraces, tempTraces := map[string]*core.Trace{}, map[string]*core.Trace{}
+	for _, p := range spec.Inputs.PUETraces {
+		for region, tr := range loader.LoadPUETracesFromCSV(p) {
+			pueTraces[region] = tr
+		}
+	}
+	for _, p := range spec.Inputs.TempTraces {
+		for region, tr := range loader.LoadTempTracesFromCSV(p) {
+			tempTraces[region] = tr
+		}
+	}
+	loader.AttachPUE(sites, pueTraces, tempTraces)
+	loader.AttachSites(baseNodes, sites)
+
+	// Workloads are prepared once per run seed, before any run starts
+	type runInput struct {
+		wls      []core.Workload
+		ciOrigin time.Time
+		fc       forecast.Forecaster
+	}
+	tasks := spec.Tasks(variants)
+	inputs := map[int64]*runInput{}
+	for _, t := range tasks {
+		if inputs[t.RunSeed] != nil {
+			continue
+		}
+		wlCSV := spec.Inputs.Workloads
+		if wlCSV == "" {
+			wlCSV = "config/workloads.csv"
+			if len(spec.Seeds)*spec.Repetitions > 1 {
+				wlCSV = fmt.Sprintf("config/workloads_%d.csv", t.RunSeed)
+			}
+			if err := generator.GenerateWorkloads(wlCSV, t.RunSeed, core.FixedClock(wlStart)); err != nil {
+				log.Fatalf("workload generation failed: %v", err)
+			}
+		}
+		in := &runInput{wls: loadWorkloads(wlCSV, spec.Workload)}
 
-	// Load workloads once
-	wls := loader.LoadWorkloadsFromCSV(wlCSV)
+		// stochastic CI paths start at the first submission (hour-aligned)
+		for _, w := range in.wls {
+			if in.ciOrigin.IsZero() || w.SubmitTime.Before(in.ciOrigin) {
+				in.ciOrigin = w.SubmitTime
+			}
+		}
+		in.ciOrigin = in.ciOrigin.Truncate(time.Hour)
 
-	// Apply duration overrides (NEW)
-	if durScale != 1.0 {
-		for i := range wls {
-			wls[i].Duration = time.Duration(float64(wls[i].Duration) * durScale)
+		in.fc, err = forecast.Parse(spec.Forecaster, t.RunSeed)
+		if err != nil {
+			log.Fatalf("invalid forecaster: %v", err)
 		}
-	}
-	if dset := parseDurationSliceSeconds(durationsFlag); len(dset) > 0 {
-		// deterministic assignment: round-robin
-		for i := range wls {
-			wls[i].Duration = dset[i%len(dset)]
+		if _, err := predict.Parse(spec.Predictor, t.RunSeed); err != nil {
+			log.Fatalf("invalid predictor: %v", err)
 		}
+		inputs[t.RunSeed] = in
 	}
 
 	// Prepare top-level results directory and subfolder for this run
 	ts := time.Now().Unix()
-	topDir := "results"
+	topDir := spec.OutputDir
 	runDir := filepath.Join(topDir, fmt.Sprintf("%d_results", ts))
 	if err := os.MkdirAll(runDir, 0755); err != nil {
 		log.Fatalf("failed to create run results dir: %v", err)
 	}
 
-	// Summary CSV for the sweep
+	// Keep the spec next to the results it produced
+	if rawSpec == nil {
+		rawSpec = spec.Marshal()
+	}
+	specOut := filepath.Join(runDir, fmt.Sprintf("%d_spec.json", ts))
+	if err := os.WriteFile(specOut, rawSpec, 0644); err != nil {
+		log.Fatalf("failed to write spec copy: %v", err)
+	}
+
+	// Create and open summary CSV (in top-level results dir)
 	summaryPath := filepath.Join(topDir, fmt.Sprintf("%d_ci_sweep_summary.csv", ts))
 	summaryFile, err := os.Create(summaryPath)
 	if err != nil {
@@ -127,199 +492,197 @@ func main() {
 	defer summaryWriter.Flush()
 
 	// Write summary header
-	summaryWriter.Write([]string{
-		"ci_weight", "batch_size", "scheduler",
-		"avg_wait_s", "avg_runtime_s", "total_ci_cost", "avg_solve_ms",
+	header := []string{"ci_weight", "batch_size", "scheduler", "seed", "rep"}
+	for _, m := range columns {
+		header = append(header, m.name)
+	}
+	summaryWriter.Write(header)
+
+	// Run the sweep on a worker pool; Ctrl-C stops handing out runs, cuts
+	// the running ones short and keeps the rows of finished runs
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	multiRun := len(spec.Seeds)*spec.Repetitions > 1
+	rows := make([][]string, len(tasks))
+	vals := make([][]float64, len(tasks))
+	bySite := make([][]siteRow, len(tasks))
+	started := time.Now()
+	err = experiment.RunPool(ctx, workers, tasks, func(ctx context.Context, t experiment.Task) error {
+		in := inputs[t.RunSeed]
+		nodes := core.CloneNodes(baseNodes)
+		loader.AttachCIProcesses(nodes, t.RunSeed, in.ciOrigin)
+
+		// predictors learn from the run's completions, so each run gets its own
+		rp, _ := predict.Parse(spec.Predictor, t.RunSeed)
+		// preempted jobs log a segment per run; the job-level metrics and
+		// CSV use one merged entry per job
+		segments, solveMs, err := runPolicy(ctx, nodes, t.Variant.Factory, t.Variant.WithCIWeight(t.CIWeight), in.fc, rp, prio, preempt, t.Variant.Queue, t.Batch, in.wls)
+		if err != nil {
+			return err
+		}
+		logs := metrics.MergeSegments(segments)
+
+		row := []string{fmt.Sprintf("%g", t.CIWeight), fmt.Sprintf("%d", t.Batch), t.Variant.Label, fmt.Sprint(t.Seed), fmt.Sprint(t.Rep)}
+		res := runResult{logs: logs, segments: segments, solveMs: solveMs, caps: caps, shares: shares, acct: metrics.Account(segments, nodes, 0)}
+		v := make([]float64, len(columns))
+		for k, m := range columns {
+			v[k] = m.fn(res)
+			row = append(row, fmt.Sprintf(m.format, v[k]))
+		}
+		rows[t.Index], vals[t.Index] = row, v
+		bySite[t.Index] = siteRows(segments, res.acct)
+
+		// Write per-run job-level CSV
+		name := fmt.Sprintf("%d_%s_%.2f_%d", ts, t.Variant.Label, t.CIWeight, t.Batch)
+		if multiRun {
+			name += fmt.Sprintf("_s%d_r%d", t.Seed, t.Rep)
+		}
+		writeRunCSV(filepath.Join(runDir, name+"_results.csv"), t.Variant.Label, logs, res.acct)
+		if utilStep > 0 {
+			writeUtil(filepath.Join(runDir, name+"_util.csv"), segments, caps, utilStep)
+		}
+		return nil
+	}, func(done int, t experiment.Task, err error) {
+		if err != nil {
+			log.Printf("[%d/%d] %s ci=%g batch=%d seed=%d rep=%d: %v", done, len(tasks), t.Variant.Label, t.CIWeight, t.Batch, t.Seed, t.Rep, err)
+			return
+		}
+		elapsed := time.Since(started)
+		eta := time.Duration(float64(elapsed) / float64(done) * float64(len(tasks)-done))
+		log.Printf("[%d/%d] %s ci=%g batch=%d seed=%d rep=%d done (elapsed %s, eta %s)",
+			done, len(tasks), t.Variant.Label, t.CIWeight, t.Batch, t.Seed, t.Rep,
+			elapsed.Round(time.Second), eta.Round(time.Second))
 	})
 
-	// Sweep configurations
-	for _, ciW := range ciWeights {
-		for _, bs := range batchSizes {
-			// Define scheduler specs
-			specs := []struct {
-				name string
-				run  func([]core.Workload) ([]core.LogEntry, float64)
-			}{
-				{
-					name: "carbonscaler",
-					run: func(workloads []core.Workload) ([]core.LogEntry, float64) {
-						nodes := loader.LoadNodesFromCSV(nodesCSV)
-						sites := loader.LoadSitesFromCSV("config/sites.csv")
-						loader.AttachSites(nodes, sites)
-
-						pol := &carbonscaler.Policy{Cfg: carbonscaler.Config{Lambda: ciW}}
-
-						sim := &core.BaseSim{}
-						sim.Init(nodes, pol) // ensure consistent init
-						sim.SetScheduleBatchSize(bs)
-						sim.CICalc = func(n *core.SimulatedNode, w core.Workload, at time.Time) float64 {
-							return metrics.ComputeCICost(n, w, at)
-						}
-						for _, j := range workloads {
-							sim.AddWorkload(j)
-						}
-
-						start := time.Now()
-						sim.Run()
-						return sim.Logs(), float64(time.Since(start).Milliseconds())
-					},
-				},
-				{
-					name: "ci_aware",
-					run: func(w []core.Workload) ([]core.LogEntry, float64) {
-						nodes := loader.LoadNodesFromCSV(nodesCSV)
-						sites := loader.LoadSitesFromCSV("config/sites.csv")
-						loader.AttachSites(nodes, sites)
-
-						// Use the swept weight (FIX)
-						pol := &cisched.Policy{
-							W:     cisched.Weights{Carbon: ciW, Wait: 0.2, Util: 0.05},
-							Scale: cisched.RobustScalingCfg{Enable: true, QLow: 0.05, QHigh: 0.95, Eps: 1e-9},
-						}
-
-						sim := &core.BaseSim{}
-						sim.Init(nodes, pol) // ensure consistent init
-						sim.SetScheduleBatchSize(bs)
-						sim.CICalc = func(n *core.SimulatedNode, w core.Workload, at time.Time) float64 {
-							return metrics.ComputeCICost(n, w, at)
-						}
-						for _, j := range w {
-							sim.AddWorkload(j)
-						}
-
-						start := time.Now()
-						sim.Run()
-						return sim.Logs(), float64(time.Since(start).Milliseconds())
-					},
-				},
-				{
-					name: "k8",
-					run: func(w []core.Workload) ([]core.LogEntry, float64) {
-						nodes := loader.LoadNodesFromCSV(nodesCSV)
-						sites := loader.LoadSitesFromCSV("config/sites.csv")
-						loader.AttachSites(nodes, sites)
-
-						pol := &k8sched.Policy{}
-
-						sim := &core.BaseSim{}
-						sim.Init(nodes, pol)
-						sim.SetScheduleBatchSize(bs)
-						sim.CICalc = func(n *core.SimulatedNode, w core.Workload, at time.Time) float64 {
-							return metrics.ComputeCICost(n, w, at)
-						}
-						for _, j := range w {
-							sim.AddWorkload(j)
-						}
-
-						start := time.Now()
-						sim.Run()
-						logs := sim.Logs()
-						elapsedMs := float64(time.Since(start).Milliseconds())
-						return logs, elapsedMs
-					},
-				},
-
-				// {
-				// 	name: "greenalg",
-				// 	run: func(jobs []core.Workload) ([]core.LogEntry, float64) {
-				// 		nodes := loader.LoadNodesFromCSV(nodesCSV)
-				// 		s := greenalg.NewGreenAlgorithms(nodes, greenalg.Config{W: greenalg.Weights{CI: 1.0, Dur: 0.2, Energy: 0.5}})
-				// 		s.SetScheduleBatchSize(bs)
-				// 		for _, j := range jobs {
-				// 			s.AddWorkload(j)
-				// 		}
-				// 		t := time.Now()
-				// 		s.Run()
-				// 		return s.Logs(), float64(time.Since(t).Milliseconds())
-				// 	},
-				// },
-				// {
-				// 	name: "ecovisor",
-				// 	run: func(jobs []core.Workload) ([]core.LogEntry, float64) {
-				// 		nodes := loader.LoadNodesFromCSV(nodesCSV)
-				// 		s := ecovisor.NewEcovisor(nodes, ecovisor.Config{W: ecovisor.Weights{SCI: 1.0, PUE: 0.3, Delay: 0.2}})
-				// 		s.SetScheduleBatchSize(bs)
-				// 		for _, j := range jobs {
-				// 			s.AddWorkload(j)
-				// 		}
-				// 		t := time.Now()
-				// 		s.Run()
-				// 		return s.Logs(), float64(time.Since(t).Milliseconds())
-				// 	},
-				// },
-				// {
-				// 	name: "energyvis",
-				// 	run: func(jobs []core.Workload) ([]core.LogEntry, float64) {
-				// 		nodes := loader.LoadNodesFromCSV(nodesCSV)
-				// 		s := energyvis.NewEnergyVis(nodes, energyvis.Config{W: energyvis.Weights{Power: 1.0, SCI: 0.3, Util: 0.2}})
-				// 		s.SetScheduleBatchSize(bs)
-				// 		for _, j := range jobs {
-				// 			s.AddWorkload(j)
-				// 		}
-				// 		t := time.Now()
-				// 		s.Run()
-				// 		return s.Logs(), float64(time.Since(t).Milliseconds())cmd/run_sim.go
-				// 	},
-				// },
+	// Summary rows are written in sweep order, whatever order runs finished in
+	for _, row := range rows {
+		if row != nil {
+			summaryWriter.Write(row)
+		}
+	}
+	summaryWriter.Flush()
+
+	// Across seeds/repetitions: mean, std and 95% CI per configuration, and
+	// paired tests between schedulers on the same runs
+	statsPath := filepath.Join(topDir, fmt.Sprintf("%d_ci_sweep_stats.csv", ts))
+	pairedPath := filepath.Join(topDir, fmt.Sprintf("%d_ci_sweep_paired.csv", ts))
+	writeStats(statsPath, tasks, vals, columns)
+	writePaired(pairedPath, tasks, vals, columns)
+	sitesPath := filepath.Join(topDir, fmt.Sprintf("%d_ci_sweep_sites.csv", ts))
+	writeSites(sitesPath, tasks, bySite)
 
-			}
+	if err != nil {
+		log.Printf("sweep interrupted (%v); summary %s holds the finished runs only", err, summaryPath)
+		return
+	}
 
-			// Run each scheduler and record metrics
-			for _, spec := range specs {
-				logs, solveMs := spec.run(wls)
-
-				// Aggregate summary metrics
-				var sumWait, sumRun, sumCI float64
-				for _, e := range logs {
-					wait := float64(e.WaitMS) / 1000.0
-					runDur := e.End.Sub(e.Start).Seconds()
-					sumWait += wait
-					sumRun += runDur
-					sumCI += e.CICost
-				}
-				n := float64(len(logs))
-
-				// Write summary row
-				summaryWriter.Write([]string{
-					fmt.Sprintf("%g", ciW),
-					fmt.Sprintf("%d", bs),
-					spec.name,
-					fmt.Sprintf("%.3f", sumWait/n),
-					fmt.Sprintf("%.3f", sumRun/n),
-					fmt.Sprintf("%.3f", sumCI),
-					fmt.Sprintf("%.3f", solveMs/n),
-				})
-
-				// Write per-run job-level CSV
-				batchFile := filepath.Join(runDir,
-					fmt.Sprintf("%d_%s_%.2f_%d_results.csv", ts, spec.name, ciW, bs),
-				)
-				bf, err := os.Create(batchFile)
-				if err != nil {
-					log.Fatalf("failed to create batch file %s: %v", batchFile, err)
-				}
-				runWriter := csv.NewWriter(bf)
-				// header with CI cost
-				runWriter.Write([]string{"job_id", "sched", "node", "submit", "start", "end", "wait_ms", "ci_cost"})
-				for _, e := range logs {
-					runWriter.Write([]string{
-						e.JobID,
-						spec.name,
-						e.Node,
-						e.Submit.Format(time.RFC3339Nano),
-						e.Start.Format(time.RFC3339Nano),
-						e.End.Format(time.RFC3339Nano),
-						fmt.Sprint(e.WaitMS),
-						fmt.Sprintf("%.3f", e.CICost),
-					})
-				}
-				runWriter.Flush()
-				bf.Close()
-				log.Printf("Wrote batch results: %s (jobs=%d)", batchFile, len(logs))
+	log.Printf("CI sweep complete; summary in %s (stats %s, paired tests %s); batch results in %s", summaryPath, statsPath, pairedPath, runDir)
+}
+
+// loadWorkloads reads the workload CSV and applies the spec's transforms
+func loadWorkloads(path string, opt experiment.Workload) []core.Workload {
+	wls := loader.LoadWorkloadsFromCSV(path)
+
+	// Apply duration overrides
+	if opt.DurScale != 1.0 {
+		for i := range wls {
+			wls[i].Duration = time.Duration(float64(wls[i].Duration) * opt.DurScale)
+		}
+	}
+	if dset := opt.DurationsS; len(dset) > 0 {
+		// deterministic assignment: round-robin
+		for i := range wls {
+			wls[i].Duration = time.Duration(dset[i%len(dset)] * float64(time.Second))
+		}
+	}
 
+	if opt.DeadlineSlack > 0 {
+		for i := range wls {
+			if wls[i].Deadline == 0 {
+				wls[i].Deadline = time.Duration(float64(wls[i].Duration) * (1 + opt.DeadlineSlack))
 			}
 		}
 	}
+	return wls
+}
+
+// runPolicy builds a registered policy on the given nodes and runs it
+// through BaseSim, returning the logs and the wall time spent (which grows
+// with contention when runs execute in parallel)
+func runPolicy(ctx context.Context, nodes []*core.SimulatedNode, f core.PolicyFactory, params core.Params, fc forecast.Forecaster, rp core.RuntimePredictor, prio *core.Multifactor, pre *core.Preemption, q core.QueueDiscipline, bs int, w []core.Workload) ([]core.LogEntry, float64, error) {
+	pol, err := f.New(params)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", f.Name, err)
+	}
+	if a, ok := pol.(forecast.Aware); ok && fc != nil {
+		a.SetForecaster(fc)
+	}
+	if b, ok := pol.(core.NodeBinder); ok {
+		b.BindNodes(nodes)
+	}
+
+	sim := &core.BaseSim{}
+	sim.Init(nodes, pol)
+	sim.SetScheduleBatchSize(bs)
+	sim.Shift = f.Shift
+	sim.Queue = q
+	sim.CICalc = func(n *core.SimulatedNode, w core.Workload, at time.Time) float64 {
+		return metrics.ComputeCICost(n, w, at)
+	}
+	sim.EnergyCalc = metrics.EnergyKWh
+	sim.Runtime = rp
+	sim.Preempt = pre
+	if prio != nil {
+		m := *prio // keeps usage, so each run gets its own
+		sim.Priority = &m
+	}
+	for _, j := range w {
+		sim.AddWorkload(j)
+	}
 
-	log.Printf("CI sweep complete; summary in %s; batch results in %s", summaryPath, runDir)
+	start := time.Now()
+	if err := sim.RunContext(ctx); err != nil {
+		return nil, 0, err
+	}
+	return sim.Logs(), float64(time.Since(start).Milliseconds()), nil
+}
+
+// writeRunCSV writes the job-level log of one run
+func writeRunCSV(path, sched string, logs []core.LogEntry, acct metrics.Accounting) {
+	bf, err := os.Create(path)
+	if err != nil {
+		log.Fatalf("failed to create batch file %s: %v", path, err)
+	}
+	defer bf.Close()
+	runWriter := csv.NewWriter(bf)
+	// header with CI cost
+	runWriter.Write([]string{"job_id", "sched", "tenant", "node", "submit", "start", "end", "wait_ms", "ci_cost", "defer_ms", "missed_deadline", "dyn_energy_kwh", "dyn_co2_g", "pred_runtime_s", "embodied_g", "sci_g", "cost_eur", "preemptions", "lost_work_s", "wasted_kwh"})
+	for _, e := range logs {
+		je := acct.Jobs[e.JobID]
+		runWriter.Write([]string{
+			e.JobID,
+			sched,
+			e.Tenant,
+			e.Node,
+			e.Submit.Format(time.RFC3339Nano),
+			e.Start.Format(time.RFC3339Nano),
+			e.End.Format(time.RFC3339Nano),
+			fmt.Sprint(e.WaitMS),
+			fmt.Sprintf("%.3f", e.CICost),
+			fmt.Sprint(e.DeferMS),
+			fmt.Sprint(e.MissedDeadline()),
+			fmt.Sprintf("%.6f", je.KWh),
+			fmt.Sprintf("%.3f", je.CO2g),
+			fmt.Sprintf("%.1f", e.PredRuntime.Seconds()),
+			fmt.Sprintf("%.3f", je.EmbodiedG),
+			fmt.Sprintf("%.3f", je.CO2g+je.EmbodiedG), // SCI: accounted dynamic emissions + embodied
+			fmt.Sprintf("%.6f", je.EUR),
+			fmt.Sprint(e.Preemptions),
+			fmt.Sprintf("%.1f", e.LostWork.Seconds()),
+			fmt.Sprintf("%.6f", e.WastedKWh),
+		})
+	}
+	runWriter.Flush()
+	log.Printf("Wrote batch results: %s (jobs=%d)", path, len(logs))
 }
@@ -0,0 +1,382 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"kube-scheduler/pkg/core"
+	"kube-scheduler/pkg/experiment"
+	"kube-scheduler/pkg/metrics"
+	"kube-scheduler/pkg/stats"
+)
+
+// runResult is what a finished run hands to the summary metrics
+type runResult struct {
+	logs     []core.LogEntry // one entry per job
+	segments []core.LogEntry // one entry per run segment of preempted jobs
+	solveMs  float64
+	caps     map[string]float64 // node name -> CPU capacity
+	shares   map[string]float64 // tenant fair-share targets
+	acct     metrics.Accounting
+}
+
+// summaryMetric is one selectable column of the sweep summary
+type summaryMetric struct {
+	name   string
+	format string
+	fn     func(r runResult) float64
+}
+
+var summaryMetrics = []summaryMetric{
+	{"avg_wait_s", "%.3f", func(r runResult) float64 {
+		sum := 0.0
+		for _, e := range r.logs {
+			sum += float64(e.WaitMS) / 1000.0
+		}
+		return sum / float64(len(r.logs))
+	}},
+	{"avg_runtime_s", "%.3f", func(r runResult) float64 {
+		sum := 0.0
+		for _, e := range r.logs {
+			sum += e.Work().Seconds()
+		}
+		return sum / float64(len(r.logs))
+	}},
+	{"total_ci_cost", "%.3f", func(r runResult) float64 { return metrics.TotalCO2(r.logs) }},
+	{"avg_solve_ms", "%.3f", func(r runResult) float64 { return r.solveMs / float64(len(r.logs)) }},
+	{"deadline_misses", "%.0f", func(r runResult) float64 {
+		misses := 0
+		for _, e := range r.logs {
+			if e.MissedDeadline() {
+				misses++
+			}
+		}
+		return float64(misses)
+	}},
+	{"avg_defer_s", "%.3f", func(r runResult) float64 {
+		sum := 0.0
+		for _, e := range r.logs {
+			sum += float64(e.DeferMS) / 1000.0
+		}
+		return sum / float64(len(r.logs))
+	}},
+	{"makespan_s", "%.0f", func(r runResult) float64 { return metrics.Makespan(r.logs).Seconds() }},
+	{"cluster_util", "%.4f", func(r runResult) float64 {
+		u, _ := metrics.Utilisation(r.segments, r.caps)
+		return u
+	}},
+	{"bsld_mean", "%.3f", func(r runResult) float64 {
+		return metrics.MeanBoundedSlowdown(r.logs, metrics.DefaultSlowdownTau)
+	}},
+	{"wait_p50_s", "%.3f", func(r runResult) float64 { return metrics.WaitPercentile(r.logs, 0.50) }},
+	{"wait_p95_s", "%.3f", func(r runResult) float64 { return metrics.WaitPercentile(r.logs, 0.95) }},
+	{"wait_p99_s", "%.3f", func(r runResult) float64 { return metrics.WaitPercentile(r.logs, 0.99) }},
+	{"deadline_miss_rate", "%.4f", func(r runResult) float64 { return metrics.DeadlineMissRate(r.logs) }},
+	{"jain_tag", "%.4f", func(r runResult) float64 { return metrics.JainByTag(r.logs, metrics.DefaultSlowdownTau) }},
+	// fairness between tenants (tenant label, else tag)
+	{"jain_tenant", "%.4f", func(r runResult) float64 { return metrics.JainByTenant(r.logs, metrics.DefaultSlowdownTau) }},
+	{"tenant_wait_max_s", "%.3f", func(r runResult) float64 { return metrics.MaxTenantWait(r.logs) }},
+	{"share_dev", "%.4f", func(r runResult) float64 { return metrics.ShareDeviation(r.segments, r.shares) }},
+	{"runtime_pred_mape", "%.4f", func(r runResult) float64 { return metrics.RuntimePredictionError(r.logs) }},
+	// energy and emissions from the accountant: idle power charged once per
+	// node over the whole run, dynamic power attributed to jobs
+	{"energy_kwh", "%.3f", func(r runResult) float64 { return r.acct.TotalKWh() }},
+	{"energy_idle_kwh", "%.3f", func(r runResult) float64 { return r.acct.IdleKWh }},
+	{"energy_dynamic_kwh", "%.3f", func(r runResult) float64 { return r.acct.DynamicKWh }},
+	// facility energy with the site PUE × k (time-varying where configured);
+	// pue_eff is the energy-weighted PUE × k of the run
+	{"facility_kwh", "%.3f", func(r runResult) float64 { return r.acct.FacilityKWh }},
+	{"pue_eff", "%.4f", func(r runResult) float64 { return r.acct.FacilityKWh / r.acct.TotalKWh() }},
+	{"co2_g", "%.3f", func(r runResult) float64 { return r.acct.TotalCO2g() }},
+	{"co2_idle_g", "%.3f", func(r runResult) float64 { return r.acct.IdleCO2g }},
+	// co2_g follows the run's emissions mode; both accountings are reported
+	{"co2_avg_g", "%.3f", func(r runResult) float64 { return r.acct.AverageCO2g }},
+	{"co2_marginal_g", "%.3f", func(r runResult) float64 { return r.acct.MarginalCO2g }},
+	{"co2_g_per_job", "%.3f", func(r runResult) float64 { return r.acct.TotalCO2g() / float64(len(r.logs)) }},
+	{"co2_g_per_cpu_h", "%.3f", func(r runResult) float64 { return r.acct.TotalCO2g() / metrics.CPUHours(r.segments) }},
+	// embodied emissions amortised over the reserved node shares; SCI per
+	// job is (operational + embodied) / jobs
+	{"embodied_g", "%.3f", func(r runResult) float64 { return r.acct.EmbodiedG }},
+	{"sci_g_per_job", "%.3f", func(r runResult) float64 { return r.acct.SCIPerJob() }},
+	// electricity cost at the site tariffs, accounted like the emissions
+	{"cost_eur", "%.4f", func(r runResult) float64 { return r.acct.TotalEUR() }},
+	{"cost_idle_eur", "%.4f", func(r runResult) float64 { return r.acct.IdleEUR }},
+	{"cost_eur_per_job", "%.5f", func(r runResult) float64 { return r.acct.TotalEUR() / float64(len(r.logs)) }},
+	// facility energy by source; co2_g and cost_eur only charge the grid share
+	{"grid_kwh", "%.3f", func(r runResult) float64 { return r.acct.GridKWh }},
+	{"renewable_kwh", "%.3f", func(r runResult) float64 { return r.acct.RenewableKWh() }},
+	{"battery_kwh", "%.3f", func(r runResult) float64 { return r.acct.BatteryKWh() }},
+	// suspensions of preemptible jobs and the work and energy they lost
+	{"preemptions", "%.0f", func(r runResult) float64 {
+		n := 0
+		for _, e := range r.logs {
+			n += e.Preemptions
+		}
+		return float64(n)
+	}},
+	{"lost_work_s", "%.1f", func(r runResult) float64 {
+		var sum time.Duration
+		for _, e := range r.logs {
+			sum += e.LostWork
+		}
+		return sum.Seconds()
+	}},
+	{"wasted_kwh", "%.4f", func(r runResult) float64 {
+		sum := 0.0
+		for _, e := range r.logs {
+			sum += e.WastedKWh
+		}
+		return sum
+	}},
+}
+
+// selectMetrics returns the summary metrics named in names (all if empty)
+func selectMetrics(names []string) ([]summaryMetric, error) {
+	if len(names) == 0 {
+		return summaryMetrics, nil
+	}
+	var out []summaryMetric
+	for _, name := range names {
+		found := false
+		for _, m := range summaryMetrics {
+			if m.name == name {
+				out = append(out, m)
+				found = true
+				break
+			}
+		}
+		if !found {
+			var known []string
+			for _, m := range summaryMetrics {
+				known = append(known, m.name)
+			}
+			return nil, fmt.Errorf("unknown metric %q (known: %s)", name, strings.Join(known, ", "))
+		}
+	}
+	return out, nil
+}
+
+// fmtStat renders a statistic, leaving NaN (too few runs) empty
+func fmtStat(v float64) string {
+	if math.IsNaN(v) {
+		return ""
+	}
+	return fmt.Sprintf("%.6g", v)
+}
+
+// configKey identifies a (ci_weight, batch_size) cell of the sweep
+type configKey struct {
+	ciW float64
+	bs  int
+}
+
+// writeStats aggregates the finished runs over seeds and repetitions: one
+// row per (ci_weight, batch_size, scheduler) with mean, std and 95% CI of
+// every metric. vals[i] holds the metric values of tasks[i] (nil if the run
+// did not finish).
+func writeStats(path string, tasks []experiment.Task, vals [][]float64, columns []summaryMetric) {
+	f, err := os.Create(path)
+	if err != nil {
+		log.Fatalf("failed to create stats CSV: %v", err)
+	}
+	defer f.Close()
+	w := csv.NewWriter(f)
+	defer w.Flush()
+
+	header := []string{"ci_weight", "batch_size", "scheduler", "n"}
+	for _, m := range columns {
+		header = append(header, m.name+"_mean", m.name+"_std", m.name+"_ci95")
+	}
+	w.Write(header)
+
+	type cell struct {
+		configKey
+		label string
+	}
+	var order []cell
+	samples := map[cell][][]float64{}
+	for i, t := range tasks {
+		c := cell{configKey{t.CIWeight, t.Batch}, t.Variant.Label}
+		if _, ok := samples[c]; !ok {
+			order = append(order, c)
+			samples[c] = make([][]float64, len(columns))
+		}
+		if vals[i] == nil {
+			continue
+		}
+		for k, v := range vals[i] {
+			samples[c][k] = append(samples[c][k], v)
+		}
+	}
+	for _, c := range order {
+		n := 0
+		if len(columns) > 0 {
+			n = len(samples[c][0])
+		}
+		if n == 0 {
+			continue
+		}
+		row := []string{fmt.Sprintf("%g", c.ciW), fmt.Sprintf("%d", c.bs), c.label, fmt.Sprint(n)}
+		for k := range columns {
+			s := stats.Describe(samples[c][k])
+			row = append(row, fmtStat(s.Mean), fmtStat(s.Std), fmtStat(s.CI95))
+		}
+		w.Write(row)
+	}
+}
+
+// writePaired runs a paired t-test for every pair of schedulers within each
+// (ci_weight, batch_size) cell, pairing runs on the same (seed, rep) so both
+// schedulers saw the same workload and CI paths. mean_diff is a - b.
+func writePaired(path string, tasks []experiment.Task, vals [][]float64, columns []summaryMetric) {
+	f, err := os.Create(path)
+	if err != nil {
+		log.Fatalf("failed to create paired-test CSV: %v", err)
+	}
+	defer f.Close()
+	w := csv.NewWriter(f)
+	defer w.Flush()
+	w.Write([]string{"ci_weight", "batch_size", "metric", "scheduler_a", "scheduler_b", "n", "mean_diff", "ci95", "t", "p"})
+
+	type unit struct {
+		seed int64
+		rep  int
+	}
+	var keys []configKey
+	labels := map[configKey][]string{}
+	byUnit := map[configKey]map[string]map[unit][]float64{}
+	var units []unit
+	seenUnit := map[unit]bool{}
+	for i, t := range tasks {
+		k := configKey{t.CIWeight, t.Batch}
+		if byUnit[k] == nil {
+			keys = append(keys, k)
+			byUnit[k] = map[string]map[unit][]float64{}
+		}
+		if byUnit[k][t.Variant.Label] == nil {
+			labels[k] = append(labels[k], t.Variant.Label)
+			byUnit[k][t.Variant.Label] = map[unit][]float64{}
+		}
+		u := unit{t.Seed, t.Rep}
+		if !seenUnit[u] {
+			seenUnit[u] = true
+			units = append(units, u)
+		}
+		if vals[i] != nil {
+			byUnit[k][t.Variant.Label][u] = vals[i]
+		}
+	}
+
+	for _, k := range keys {
+		ls := labels[k]
+		for m, col := range columns {
+			for i := 0; i < len(ls); i++ {
+				for j := i + 1; j < len(ls); j++ {
+					var a, b []float64
+					for _, u := range units {
+						va, okA := byUnit[k][ls[i]][u]
+						vb, okB := byUnit[k][ls[j]][u]
+						if okA && okB {
+							a = append(a, va[m])
+							b = append(b, vb[m])
+						}
+					}
+					if len(a) == 0 {
+						continue
+					}
+					r := stats.PairedTTest(a, b)
+					w.Write([]string{
+						fmt.Sprintf("%g", k.ciW), fmt.Sprintf("%d", k.bs), col.name, ls[i], ls[j],
+						fmt.Sprint(r.N), fmtStat(r.MeanDiff), fmtStat(r.CI95), fmtStat(r.T), fmtStat(r.P),
+					})
+				}
+			}
+		}
+	}
+}
+
+// writeSites writes the per-site breakdown of every finished run
+func writeSites(path string, tasks []experiment.Task, sites [][]siteRow) {
+	f, err := os.Create(path)
+	if err != nil {
+		log.Fatalf("failed to create per-site CSV: %v", err)
+	}
+	defer f.Close()
+	w := csv.NewWriter(f)
+	defer w.Flush()
+	w.Write([]string{"ci_weight", "batch_size", "scheduler", "seed", "rep", "site", "jobs", "energy_kwh", "co2_g", "cpu_hours", "avg_wait_s",
+		"renewable_kwh", "battery_kwh", "grid_kwh", "curtailed_kwh"})
+	for i, t := range tasks {
+		for _, r := range sites[i] {
+			w.Write([]string{
+				fmt.Sprintf("%g", t.CIWeight), fmt.Sprintf("%d", t.Batch), t.Variant.Label, fmt.Sprint(t.Seed), fmt.Sprint(t.Rep),
+				r.site, fmt.Sprint(r.Jobs), fmt.Sprintf("%.3f", r.EnergyKWh), fmt.Sprintf("%.3f", r.CO2g),
+				fmt.Sprintf("%.3f", r.CPUHours), fmt.Sprintf("%.3f", r.AvgWaitS),
+				r.supply(func(s metrics.SiteSupply) float64 { return s.RenewableKWh }),
+				r.supply(func(s metrics.SiteSupply) float64 { return s.BatteryKWh }),
+				r.supply(func(s metrics.SiteSupply) float64 { return s.GridKWh }),
+				r.supply(func(s metrics.SiteSupply) float64 { return s.CurtailedKWh }),
+			})
+		}
+	}
+}
+
+// siteRow is one site of a run's per-site breakdown
+type siteRow struct {
+	site string
+	metrics.SiteBreakdown
+	onSite *metrics.SiteSupply // nil without renewables or a battery
+}
+
+// supply formats a field of the site's on-site supply (empty without one)
+func (r siteRow) supply(f func(metrics.SiteSupply) float64) string {
+	if r.onSite == nil {
+		return ""
+	}
+	return fmt.Sprintf("%.3f", f(*r.onSite))
+}
+
+// siteRows orders a per-site breakdown by site ID
+func siteRows(logs []core.LogEntry, acct metrics.Accounting) []siteRow {
+	by := metrics.BySite(logs, acct)
+	out := make([]siteRow, 0, len(by))
+	for id, b := range by {
+		r := siteRow{site: id, SiteBreakdown: b}
+		if s, ok := acct.OnSite[id]; ok {
+			r.onSite = &s
+		}
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].site < out[j].site })
+	return out
+}
+
+// writeUtil writes a run's CPU utilisation over time, cluster and per node
+func writeUtil(path string, logs []core.LogEntry, caps map[string]float64, step time.Duration) {
+	f, err := os.Create(path)
+	if err != nil {
+		log.Fatalf("failed to create utilisation CSV: %v", err)
+	}
+	defer f.Close()
+	w := csv.NewWriter(f)
+	defer w.Flush()
+	names := make([]string, 0, len(caps))
+	for name := range caps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	w.Write(append([]string{"time", "cluster"}, names...))
+	for _, s := range metrics.UtilisationSeries(logs, caps, step) {
+		row := []string{s.Time.Format(time.RFC3339), fmt.Sprintf("%.4f", s.Cluster)}
+		for _, name := range names {
+			row = append(row, fmt.Sprintf("%.4f", s.Nodes[name]))
+		}
+		w.Write(row)
+	}
+}
@@ -6,22 +6,34 @@ import (
 	"math"
 
 	"kube-scheduler/pkg/core"
+	"kube-scheduler/pkg/forecast"
 	"kube-scheduler/pkg/metrics"
 )
 
-type Config struct{ Lambda float64 }
+type Config struct {
+    Lambda float64
 
-type Policy struct{ Cfg Config }
+    // Temporal shifting, used when BaseSim.Shift is on.
+    ShiftStep    time.Duration // spacing of candidate start times (default 15m)
+    ShiftMinGain float64       // minimum relative CO₂ saving worth waiting for
+}
+
+// maxShiftCandidates bounds the start times Defer evaluates per job.
+const maxShiftCandidates = 288
+
+type Policy struct {
+    Cfg Config
+
+    // Forecast, if set, prices carbon at the forecast mean CI over the job's
+    // window (both for placement and for choosing a shifted start time).
+    Forecast forecast.Forecaster
+}
 
 func (p *Policy) Name() string { return "carbonscaler" }
 
-func (p *Policy) Score(_ context.Context, j core.Job, nodes []core.SimulatedNode) (core.Scores, error) {
-    w := core.Workload{
-        ID: j.ID, CPU: j.CPUReq, Memory: j.MemReq,
-        Duration: time.Duration(j.EstimatedDuration * float64(time.Second)),
-        SubmitTime: j.SubmitAt, Labels: j.Labels,
-    }
-    now := time.Now()
+func (p *Policy) Score(ctx context.Context, j core.Job, nodes []core.SimulatedNode) (core.Scores, error) {
+    w := workloadOf(j)
+    now := core.Now(ctx)
 
     type row struct {
         id       string
@@ -38,7 +50,7 @@ func (p *Policy) Score(_ context.Context, j core.Job, nodes []core.SimulatedNode
         if n.TotalCPU > 0 { used += (n.TotalCPU - n.AvailableCPU) / n.TotalCPU }
         if n.TotalMemory > 0 { used += (n.TotalMemory - n.AvailableMemory) / n.TotalMemory }
         // IMPORTANT: same CI model as logs/CI-Aware (includes PUE * k and time-varying profile)
-        cic := metrics.ComputeCICost(&n, w, now)
+        cic := p.ciCost(&n, w, now, now)
         feats = append(feats, row{id:n.Name, util:used, cicost:cic, ok:true})
     }
 
@@ -63,6 +75,69 @@ func (p *Policy) Score(_ context.Context, j core.Job, nodes []core.SimulatedNode
 
 func (p *Policy) Select(sc core.Scores) (string, bool) { return core.ArgMin(sc) }
 
+// Defer implements core.Deferrer (suspend/shift, "wait awhile"): among start
+// times now, now+step, … up to the job's latest start, find the one with the
+// lowest CI cost on any node that can take the job, and wait for it if it
+// beats starting now by more than ShiftMinGain.
+func (p *Policy) Defer(ctx context.Context, j core.Job, nodes []core.SimulatedNode) (time.Time, bool) {
+    now := core.Now(ctx)
+    latest := j.LatestStart()
+    if latest.IsZero() || !latest.After(now) {
+        return time.Time{}, false
+    }
+    step := p.Cfg.ShiftStep
+    if step <= 0 { step = 15 * time.Minute }
+    if n := latest.Sub(now) / step; n > maxShiftCandidates {
+        step = latest.Sub(now) / maxShiftCandidates
+    }
+
+    w := workloadOf(j)
+    cost := func(t time.Time) float64 {
+        best := math.Inf(1)
+        for i := range nodes {
+            if nodes[i].CanAccept(w) {
+                best = math.Min(best, p.ciCost(&nodes[i], w, now, t))
+            }
+        }
+        return best
+    }
+
+    nowCost := cost(now)
+    if math.IsInf(nowCost, 1) {
+        return time.Time{}, false
+    }
+    bestCost, bestT := nowCost, now
+    for t := now.Add(step); !t.After(latest); t = t.Add(step) {
+        if c := cost(t); c < bestCost {
+            bestCost, bestT = c, t
+        }
+    }
+    if bestT.Equal(now) || nowCost-bestCost <= p.Cfg.ShiftMinGain*nowCost {
+        return time.Time{}, false
+    }
+    return bestT, true
+}
+
+// ciCost is the CI cost of starting w on n at start, as known at now:
+// the forecast window mean if a Forecaster is set, else CI at start.
+func (p *Policy) ciCost(n *core.SimulatedNode, w core.Workload, now, start time.Time) float64 {
+    if p.Forecast == nil {
+        return metrics.ComputeCICost(n, w, start)
+    }
+    mean := p.Forecast.Forecast(forecast.NodeSeries(n), now, start, w.Duration)
+    return metrics.CICostWithCI(n, w, start, mean)
+}
+
+// workloadOf adapts Job -> Workload so CanAccept/ComputeCICost work unchanged.
+func workloadOf(j core.Job) core.Workload {
+    return core.Workload{
+        ID: j.ID, CPU: j.CPUReq, Memory: j.MemReq,
+        Duration: time.Duration(j.EstimatedDuration * float64(time.Second)),
+        SubmitTime: j.SubmitAt, Labels: j.Labels,
+        Deadline: time.Duration(j.DeadlineMs) * time.Millisecond,
+    }
+}
+
 
 // Adapter methods
 // func (s *Simulator) SetScheduleBatchSize(n int)  { s.base.SetScheduleBatchSize(n) }
@@ -0,0 +1,41 @@
+package carbonscaler
+
+import (
+	"kube-scheduler/pkg/core"
+	"kube-scheduler/pkg/forecast"
+)
+
+// SetForecaster implements forecast.Aware.
+func (p *Policy) SetForecaster(f forecast.Forecaster) { p.Forecast = f }
+
+var params = []core.ParamSpec{
+	{Name: "lambda", Kind: core.ParamFloat, Default: "1.0", Doc: "weight of the normalised CI cost against utilisation"},
+	{Name: "shift_step", Kind: core.ParamDuration, Default: "15m", Doc: "spacing of candidate start times when shifting"},
+	{Name: "shift_min_gain", Kind: core.ParamFloat, Default: "0.05", Doc: "minimum relative CO₂ saving worth deferring for"},
+}
+
+func newPolicy(p core.Params) (core.Policy, error) {
+	return &Policy{Cfg: Config{
+		Lambda:       p.Float("lambda"),
+		ShiftStep:    p.Duration("shift_step"),
+		ShiftMinGain: p.Float("shift_min_gain"),
+	}}, nil
+}
+
+func init() {
+	core.RegisterPolicy(core.PolicyFactory{
+		Name:     "carbonscaler",
+		Doc:      "least-loaded placement penalised by min–max normalised CI cost",
+		Params:   params,
+		CIWeight: "lambda",
+		New:      newPolicy,
+	})
+	core.RegisterPolicy(core.PolicyFactory{
+		Name:     "carbonscaler_shift",
+		Doc:      "carbonscaler with temporal shifting of jobs that have a deadline",
+		Params:   params,
+		CIWeight: "lambda",
+		Shift:    true,
+		New:      newPolicy,
+	})
+}
@@ -3,19 +3,19 @@ package cisched
 
 import (
 	"context"
-	"fmt"
 	"math"
 	"sort"
 	"time"
 
 	"kube-scheduler/pkg/core"
+	"kube-scheduler/pkg/forecast"
 	"kube-scheduler/pkg/metrics"
 )
 
 // Score implements the CI-Aware scorer with robust scaling and a soft util/queue guard.
 // NOTE: We adapt Job -> Workload so CanAccept() (which expects Workload) works.
-func (p *Policy) Score(_ context.Context, j core.Job, nodes []core.SimulatedNode) (core.Scores, error) {
-	now := time.Now()
+func (p *Policy) Score(ctx context.Context, j core.Job, nodes []core.SimulatedNode) (core.Scores, error) {
+	now := core.Now(ctx) // simulated time under BaseSim, wall time otherwise
 
 	// Job -> Workload adaptation (keeps your Job struct unchanged).
 	w := core.Workload{
@@ -32,6 +32,8 @@ func (p *Policy) Score(_ context.Context, j core.Job, nodes []core.SimulatedNode
 		ciCostG     float64
 		waitSeconds float64
 		utilOrQueue float64
+		embodiedG   float64
+		costEUR     float64
 		skip        bool
 	}
 	features := make([]feat, 0, len(nodes))
@@ -44,6 +46,10 @@ func (p *Policy) Score(_ context.Context, j core.Job, nodes []core.SimulatedNode
 
 		// 1) Carbon impact (your ComputeCICost should already apply PUE*K and region CI).
 		ci := metrics.ComputeCICost(&n, w, now) // grams CO₂
+		if p.Forecast != nil {
+			mean := p.Forecast.Forecast(forecast.NodeSeries(&n), now, now, w.Duration)
+			ci = metrics.CICostWithCI(&n, w, now, mean)
+		}
 
 		// 2) Wait proxy (0 when free).
 		waitS := 0.0
@@ -54,16 +60,24 @@ func (p *Policy) Score(_ context.Context, j core.Job, nodes []core.SimulatedNode
 		// 3) Soft guard: utilisation preferred; else queue length (both will be scaled).
 		guard := utilisationOrQueue(n)
 
+		// 4) Embodied carbon amortised over the reserved share of the node.
+		embodied := metrics.EmbodiedG(&n, w)
+
+		// 5) Electricity cost at the site tariff over the job's window.
+		cost := metrics.CostEUR(&n, w, now)
+
 		features = append(features, feat{
 			key:         nodeKey(n),
 			ciCostG:     ci,
 			waitSeconds: waitS,
 			utilOrQueue: guard,
+			embodiedG:   embodied,
+			costEUR:     cost,
 		})
 	}
 
 	// Collect for scaling (ignore skipped).
-	var cis, waits, utils []float64
+	var cis, waits, utils, embodied, costs []float64
 	for _, f := range features {
 		if f.skip {
 			continue
@@ -71,6 +85,8 @@ func (p *Policy) Score(_ context.Context, j core.Job, nodes []core.SimulatedNode
 		cis = append(cis, f.ciCostG)
 		waits = append(waits, f.waitSeconds)
 		utils = append(utils, f.utilOrQueue)
+		embodied = append(embodied, f.embodiedG)
+		costs = append(costs, f.costEUR)
 	}
 
 	// Robust (5–95) or min–max fallback.
@@ -92,6 +108,8 @@ func (p *Policy) Score(_ context.Context, j core.Job, nodes []core.SimulatedNode
 	ciScaler := buildScaler(cis, scale)
 	waitScaler := buildScaler(waits, scale)
 	utilScaler := buildScaler(utils, scale)
+	embodiedScaler := buildScaler(embodied, scale)
+	costScaler := buildScaler(costs, scale)
 
 	// Compose (lower is better).
 	sc := core.Scores{} // map[string]float64
@@ -103,8 +121,10 @@ func (p *Policy) Score(_ context.Context, j core.Job, nodes []core.SimulatedNode
 		ciZ := ciScaler(f.ciCostG)
 		waitZ := waitScaler(f.waitSeconds)
 		utilZ := utilScaler(f.utilOrQueue)
+		embodiedZ := embodiedScaler(f.embodiedG)
+		costZ := costScaler(f.costEUR)
 
-		score := p.W.Carbon*ciZ + p.W.Wait*waitZ + p.W.Util*utilZ
+		score := p.W.Carbon*ciZ + p.W.Wait*waitZ + p.W.Util*utilZ + p.W.Embodied*embodiedZ + p.W.Price*costZ
 		sc[f.key] = score
 	}
 
@@ -113,14 +133,14 @@ func (p *Policy) Score(_ context.Context, j core.Job, nodes []core.SimulatedNode
 
 // ----------------- helpers -----------------
 
+// nodeKey must match what BaseSim resolves scores against (SimulatedNode.Name).
+// SimulatedNode has Name/ID fields, not methods, so probing for methods fell
+// through to a per-call pointer key that never matched any node.
 func nodeKey(n core.SimulatedNode) string {
-	if v, ok := any(n).(interface{ ID() string }); ok {
-		return v.ID()
-	}
-	if v, ok := any(n).(interface{ Name() string }); ok {
-		return v.Name()
+	if n.Name != "" {
+		return n.Name
 	}
-	return fmt.Sprintf("%p", &n)
+	return n.ID
 }
 
 func nextReleaseAfter(n core.SimulatedNode, t time.Time) time.Duration {
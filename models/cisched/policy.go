@@ -1,10 +1,14 @@
 package cisched
 
+import "kube-scheduler/pkg/forecast"
+
 // Weights for the score terms (all inputs are normalised 0..1 before weighting).
 type Weights struct {
-	Carbon float64 // carbon-impact term
-	Wait   float64 // wait proxy term
-	Util   float64 // utilisation/queue guard term
+	Carbon   float64 // carbon-impact term
+	Wait     float64 // wait proxy term
+	Util     float64 // utilisation/queue guard term
+	Embodied float64 // amortised embodied-carbon term (0 = operational carbon only)
+	Price    float64 // electricity-cost term (0 = ignore tariffs)
 }
 
 // Robust scaling config (percentile-based; fallback to min–max if disabled).
@@ -19,6 +23,10 @@ type RobustScalingCfg struct {
 type Policy struct {
 	W     Weights
 	Scale RobustScalingCfg
+
+	// Forecast, if set, prices carbon at the forecast mean CI over the job's
+	// execution window instead of the instantaneous CI at placement.
+	Forecast forecast.Forecaster
 }
 
 func (p *Policy) Name() string { return "ci_aware" }
@@ -0,0 +1,31 @@
+package cisched
+
+import (
+	"kube-scheduler/pkg/core"
+	"kube-scheduler/pkg/forecast"
+)
+
+// SetForecaster implements forecast.Aware.
+func (p *Policy) SetForecaster(f forecast.Forecaster) { p.Forecast = f }
+
+func init() {
+	core.RegisterPolicy(core.PolicyFactory{
+		Name: "ci_aware",
+		Doc:  "weighted carbon, wait and utilisation terms with robust scaling",
+		Params: []core.ParamSpec{
+			{Name: "carbon", Kind: core.ParamFloat, Default: "1.0", Doc: "carbon-impact weight"},
+			{Name: "wait", Kind: core.ParamFloat, Default: "0.2", Doc: "wait-proxy weight"},
+			{Name: "util", Kind: core.ParamFloat, Default: "0.05", Doc: "utilisation/queue guard weight"},
+			{Name: "embodied", Kind: core.ParamFloat, Default: "0", Doc: "amortised embodied-carbon weight"},
+			{Name: "price", Kind: core.ParamFloat, Default: "0", Doc: "electricity-cost weight (site tariffs)"},
+			{Name: "robust", Kind: core.ParamBool, Default: "true", Doc: "percentile (5–95%) scaling instead of min–max"},
+		},
+		CIWeight: "carbon",
+		New: func(p core.Params) (core.Policy, error) {
+			return &Policy{
+				W:     Weights{Carbon: p.Float("carbon"), Wait: p.Float("wait"), Util: p.Float("util"), Embodied: p.Float("embodied"), Price: p.Float("price")},
+				Scale: RobustScalingCfg{Enable: p.Bool("robust"), QLow: 0.05, QHigh: 0.95, Eps: 1e-9},
+			}, nil
+		},
+	})
+}
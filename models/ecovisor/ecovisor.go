@@ -2,18 +2,99 @@ package ecovisor
 
 import (
 	"context"
-	api "kube-scheduler/pkg/core"
+	"math"
+	"time"
+
+	"kube-scheduler/pkg/core"
+	"kube-scheduler/pkg/metrics"
 )
 
-type CarbonScaler struct{ Lambda float64 }
-func (s *CarbonScaler) Name() string { return "carbonscaler" }
-func (s *CarbonScaler) Score(ctx context.Context, job api.Job, nodes []api.Node) (api.Scores, error) {
-    scores := api.Scores{}
-    for _, n := range nodes {
-        util := (n.Metrics["cpu_used"] / n.CPUCap) + (n.Metrics["mem_used"] / n.MemCap)
-        ci   := n.Metrics["ci_norm"] // 0..1
-        scores[n.ID] = util / (1.0 + s.Lambda*ci)
-    }
-    return scores, nil
+// Ecovisor follows Ecovisor (Souza et al., ASPLOS '23): sites expose their
+// on-site renewables, and a job's extra draw is met from its site's
+// renewable surplus before the grid. Nodes are scored by utilisation plus
+// λ × the normalised CI of the grid share of the job's draw, so nodes at
+// sites with spare generation look carbon-free. CI is normalised over the
+// range of the candidates' site traces (see ciRange).
+//
+// The green share is a placement-time estimate and can disagree with
+// metrics.Account, which dispatches generation, battery and grid step by
+// step over the run: here the surplus is the generation now minus the
+// site's draw now, batteries are ignored (charge is only known after the
+// run, so a site running on its battery looks grid-powered), and the job's
+// draw is assumed to stay at the current surplus for its whole duration.
+type Ecovisor struct{ Lambda float64 }
+
+func (s *Ecovisor) Name() string { return "ecovisor" }
+
+func (s *Ecovisor) Score(ctx context.Context, j core.Job, nodes []core.SimulatedNode) (core.Scores, error) {
+	now := core.Now(ctx)
+	w := core.Workload{CPU: j.CPUReq, Memory: j.MemReq, Duration: time.Duration(j.EstimatedDuration * float64(time.Second))}
+	draw := metrics.SiteDrawKW(nodes, now)
+	lo, hi := ciRange(nodes, now)
+
+	scores := core.Scores{}
+	for i := range nodes {
+		n := &nodes[i]
+		if !n.CanAccept(w) {
+			continue
+		}
+		util := 0.0
+		if n.TotalCPU > 0 {
+			util += (n.TotalCPU - n.AvailableCPU) / n.TotalCPU
+		}
+		if n.TotalMemory > 0 {
+			util += (n.TotalMemory - n.AvailableMemory) / n.TotalMemory
+		}
+		ciNorm := 0.0
+		if hi > lo {
+			ciNorm = (metrics.CIAt(n, now) - lo) / (hi - lo)
+		}
+		scores[n.Name] = util + s.Lambda*ciNorm*(1-greenShare(n, w, draw[n.SiteID], now))
+	}
+	return scores, nil
+}
+
+func (s *Ecovisor) Select(sc core.Scores) (string, bool) { return core.ArgMin(sc) }
+
+// ciRange bounds the CI of nodes: the sample range of their sites' traces in
+// the run's emissions mode, widened to the nodes' CI at now (which covers
+// nodes without a site trace). The lower bound is 0 when all CIs are equal,
+// so λ still weighs the grid share.
+func ciRange(nodes []core.SimulatedNode, now time.Time) (lo, hi float64) {
+	lo, hi = math.Inf(1), math.Inf(-1)
+	seen := map[*core.Trace]bool{}
+	for i := range nodes {
+		n := &nodes[i]
+		if n.Site != nil {
+			if tr := n.Site.ActiveCI(); tr != nil && len(tr.Values) > 0 && !seen[tr] {
+				seen[tr] = true
+				l, h := tr.Range()
+				lo, hi = math.Min(lo, l), math.Max(hi, h)
+			}
+		}
+		ci := metrics.CIAt(n, now)
+		lo, hi = math.Min(lo, ci), math.Max(hi, ci)
+	}
+	if hi <= lo {
+		lo = math.Min(0, lo)
+	}
+	return lo, hi
+}
+
+// greenShare is the fraction of w's added facility draw on n covered by
+// the surplus of its site's generation over siteKW.
+func greenShare(n *core.SimulatedNode, w core.Workload, siteKW float64, now time.Time) float64 {
+	surplus := metrics.RenewableKW(n, now) - siteKW
+	if surplus <= 0 {
+		return 0
+	}
+	used := 0.0
+	if n.TotalCPU > 0 {
+		used = (n.TotalCPU - n.AvailableCPU) / n.TotalCPU
+	}
+	addKW := (metrics.PowerW(n, math.Min(used+w.CPU/n.TotalCPU, 1)) - metrics.PowerW(n, used)) / 1000 * metrics.SiteFactorAt(n, now)
+	if addKW <= 0 {
+		return 1
+	}
+	return math.Min(1, surplus/addKW)
 }
-func (s *CarbonScaler) Select(sc api.Scores) (string, bool) { return api.ArgMin(sc) }
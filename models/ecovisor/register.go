@@ -0,0 +1,19 @@
+package ecovisor
+
+import (
+	"kube-scheduler/pkg/core"
+)
+
+func init() {
+	core.RegisterPolicy(core.PolicyFactory{
+		Name: "ecovisor",
+		Doc:  "utilisation plus λ × normalised CI of the job's draw not covered by on-site renewable surplus",
+		Params: []core.ParamSpec{
+			{Name: "lambda", Kind: core.ParamFloat, Default: "1.0", Doc: "weight of the grid share's normalised CI"},
+		},
+		CIWeight: "lambda",
+		New: func(p core.Params) (core.Policy, error) {
+			return &Ecovisor{Lambda: p.Float("lambda")}, nil
+		},
+	})
+}
@@ -14,42 +14,36 @@ type SchedulerType int
 
 const (
 	Kubernetes SchedulerType = iota // least-loaded
-	Swarm                            // most-loaded (simple backfill)
+	Swarm                           // most-loaded (bin packing; see core.QueueEASY for backfilling)
 )
 
-// EventType defines arrival or end.
-type EventType int
+// EventType defines arrival or end; it is the shared core event kind.
+type EventType = core.EventKind
 
 const (
-	JobArrival EventType = iota
-	JobEnd
+	JobArrival = core.EventArrival
+	JobEnd     = core.EventCompletion
 )
 
 // Event drives the discrete-event simulation.
-type Event struct {
-	Time     time.Time
-	Type     EventType
-	Workload core.Workload
-	Node     *core.SimulatedNode
-}
+type Event = core.Event
 
-// DiscreteEventScheduler drives simulation.
+// DiscreteEventScheduler drives simulation on the core event kernel
+// (embedded: Clock is the simulation time, Events the heap-ordered timeline).
 type DiscreteEventScheduler struct {
-	Clock             time.Time
+	core.Kernel
 	Nodes             []*core.SimulatedNode
-	Events            []Event
 	Logs              []core.LogEntry
 	SchedType         SchedulerType
 	ScheduleBatchSize int
 	Pending           []core.Workload
 }
 
-// NewScheduler initialises with nodes and defaults.
+// NewScheduler initialises with nodes and defaults. The clock starts at the
+// zero time and follows the first event.
 func NewScheduler(nodes []*core.SimulatedNode) *DiscreteEventScheduler {
 	return &DiscreteEventScheduler{
-		Clock:             time.Now(),
 		Nodes:             nodes,
-		Events:            []Event{},
 		Logs:              []core.LogEntry{},
 		SchedType:         Kubernetes,
 		ScheduleBatchSize: 1,
@@ -59,35 +53,31 @@ func NewScheduler(nodes []*core.SimulatedNode) *DiscreteEventScheduler {
 
 // AddWorkload enqueues an arrival event.
 func (s *DiscreteEventScheduler) AddWorkload(w core.Workload) {
-	s.Events = append(s.Events, Event{Time: w.SubmitTime, Type: JobArrival, Workload: w})
+	s.Schedule(Event{Time: w.SubmitTime, Kind: JobArrival, Workload: w})
 }
 
-// Run executes all events and flushes the final batch.
+// Run executes the events a timestamp at a time, as core.BaseSim does:
+// reservations ending by then are released (and pending jobs backfilled),
+// every arrival due is queued, then one scheduling pass runs. The final
+// batch is flushed at the end.
 func (s *DiscreteEventScheduler) Run() {
-	s.sortEvents()
-	for len(s.Events) > 0 {
-		e := s.Events[0]
-		s.Events = s.Events[1:]
-		s.Clock = e.Time
+	for {
+		batch := s.NextBatch()
+		if len(batch) == 0 {
+			break
+		}
 		s.processReleases(s.Clock)
-		s.handleEvent(e)
+		for _, e := range batch {
+			s.handleEvent(e)
+		}
+		if len(s.Pending) >= s.ScheduleBatchSize {
+			s.scheduleBatch()
+		}
 	}
 	// Flush any remaining pending jobs.
 	s.scheduleBatch()
 }
 
-// sortEvents keeps events time-ordered.
-func (s *DiscreteEventScheduler) sortEvents() {
-	slice := s.Events
-	for i := range slice {
-		for j := i + 1; j < len(slice); j++ {
-			if slice[j].Time.Before(slice[i].Time) {
-				slice[i], slice[j] = slice[j], slice[i]
-			}
-		}
-	}
-}
-
 // processReleases frees resources then backfills pending.
 func (s *DiscreteEventScheduler) processReleases(t time.Time) {
 	for _, n := range s.Nodes {
@@ -97,7 +87,7 @@ func (s *DiscreteEventScheduler) processReleases(t time.Time) {
 	for _, w := range s.Pending {
 		if node := s.selectNode(w); node != nil {
 			node.Reserve(w, t)
-			s.Events = append(s.Events, Event{Time: t.Add(w.Duration), Type: JobEnd, Node: node, Workload: w})
+			s.Schedule(Event{Time: t.Add(w.Duration), Kind: JobEnd, Node: node, Workload: w})
 			ciCost := metrics.ComputeCICost(node, w, t)
 			s.Logs = append(s.Logs, core.LogEntry{
 				JobID:  w.ID,
@@ -115,14 +105,11 @@ func (s *DiscreteEventScheduler) processReleases(t time.Time) {
 	s.Pending = still
 }
 
-// handleEvent schedules arrivals in batches or queues them.
+// handleEvent queues arrivals for the next scheduling pass.
 func (s *DiscreteEventScheduler) handleEvent(e Event) {
-	switch e.Type {
+	switch e.Kind {
 	case JobArrival:
 		s.Pending = append(s.Pending, e.Workload)
-		if len(s.Pending) >= s.ScheduleBatchSize {
-			s.scheduleBatch()
-		}
 	case JobEnd:
 		log.Printf("Job %s ended on %s at %v", e.Workload.ID, e.Node.Name, s.Clock)
 	}
@@ -138,7 +125,7 @@ func (s *DiscreteEventScheduler) scheduleBatch() {
 		if node := s.selectNode(w); node != nil {
 			t := s.Clock
 			node.Reserve(w, t)
-			s.Events = append(s.Events, Event{Time: t.Add(w.Duration), Type: JobEnd, Node: node, Workload: w})
+			s.Schedule(Event{Time: t.Add(w.Duration), Kind: JobEnd, Node: node, Workload: w})
 			ciCost := metrics.ComputeCICost(node, w, t)
 			s.Logs = append(s.Logs, core.LogEntry{
 				JobID:  w.ID,
@@ -184,7 +171,7 @@ func (s *DiscreteEventScheduler) scheduleKubernetes(w core.Workload) *core.Simul
 	return best
 }
 
-// scheduleSwarm: most-loaded heuristic (simple backfill).
+// scheduleSwarm: most-loaded heuristic (bin packing, no reservations).
 func (s *DiscreteEventScheduler) scheduleSwarm(w core.Workload) *core.SimulatedNode {
 	var best *core.SimulatedNode
 	bestScore := -1.0
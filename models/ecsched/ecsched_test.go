@@ -0,0 +1,98 @@
+package ecsched
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"kube-scheduler/models/k8sched"
+	"kube-scheduler/pkg/core"
+)
+
+type placement struct {
+	job, node  string
+	start, end time.Time
+}
+
+func nodes() []*core.SimulatedNode {
+	return []*core.SimulatedNode{
+		core.NewNode("n0", 4, 8, 100),
+		core.NewNode("n1", 8, 16, 100),
+		core.NewNode("n2", 4, 8, 100),
+	}
+}
+
+// TestPlacementOrderMatchesBaseSim runs the Kubernetes heuristic here and
+// the k8 policy in core.BaseSim on the same workloads and expects the same
+// placements in the same order. The workloads stress the event ordering:
+// bursts of simultaneous arrivals, arrivals at the instant jobs complete,
+// and jobs that queue until capacity frees.
+func TestPlacementOrderMatchesBaseSim(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name string
+		jobs []core.Workload
+	}{
+		{"simultaneous arrivals", []core.Workload{
+			{ID: "a", SubmitTime: t0, CPU: 2, Memory: 2, Duration: time.Minute},
+			{ID: "b", SubmitTime: t0, CPU: 4, Memory: 4, Duration: time.Minute},
+			{ID: "c", SubmitTime: t0, CPU: 1, Memory: 1, Duration: time.Minute},
+			{ID: "d", SubmitTime: t0, CPU: 3, Memory: 6, Duration: time.Minute},
+		}},
+		{"arrivals at completions", []core.Workload{
+			{ID: "a", SubmitTime: t0, CPU: 4, Memory: 8, Duration: time.Minute},
+			{ID: "b", SubmitTime: t0, CPU: 8, Memory: 16, Duration: 2 * time.Minute},
+			{ID: "c", SubmitTime: t0, CPU: 4, Memory: 8, Duration: time.Minute},
+			{ID: "d", SubmitTime: t0.Add(time.Minute), CPU: 4, Memory: 4, Duration: time.Minute},
+			{ID: "e", SubmitTime: t0.Add(time.Minute), CPU: 2, Memory: 2, Duration: time.Minute},
+			{ID: "f", SubmitTime: t0.Add(2 * time.Minute), CPU: 8, Memory: 8, Duration: time.Minute},
+		}},
+		{"queued until capacity frees", func() []core.Workload {
+			var ws []core.Workload
+			for i := 0; i < 24; i++ {
+				ws = append(ws, core.Workload{
+					ID:         fmt.Sprintf("j%02d", i),
+					SubmitTime: t0.Add(time.Duration(i/4) * 30 * time.Second),
+					CPU:        float64(1 + i%4),
+					Memory:     float64(2 + i%3),
+					Duration:   time.Duration(1+i%5) * time.Minute,
+				})
+			}
+			return ws
+		}()},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			es := NewScheduler(nodes())
+			for _, w := range tc.jobs {
+				es.AddWorkload(w)
+			}
+			es.Run()
+
+			b := &core.BaseSim{}
+			b.Init(nodes(), &k8sched.Policy{})
+			b.SetScheduleBatchSize(len(tc.jobs))
+			for _, w := range tc.jobs {
+				b.AddWorkload(w)
+			}
+			b.Run()
+
+			got, want := placements(es.Logs), placements(b.Logs())
+			if len(got) != len(tc.jobs) || len(want) != len(tc.jobs) {
+				t.Fatalf("placed %d (ecsched) and %d (BaseSim) of %d jobs", len(got), len(want), len(tc.jobs))
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Errorf("placement %d: ecsched %+v, BaseSim %+v", i, got[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func placements(logs []core.LogEntry) []placement {
+	out := make([]placement, len(logs))
+	for i, e := range logs {
+		out[i] = placement{e.JobID, e.Node, e.Start, e.End}
+	}
+	return out
+}
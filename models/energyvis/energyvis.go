@@ -11,7 +11,7 @@ func (s *EnergyVis) Score(ctx context.Context, job api.Job, nodes []api.Node) (a
     scores := api.Scores{}
     for _, n := range nodes {
         power := n.Metrics["node_power_w"]
-        sci   := n.Metrics["sci_pred"]
+        sci   := n.Metrics["sci_pred"] * 1000 // mgCO₂, the scale W.SCI was tuned at against watts
         util  := (n.Metrics["cpu_used"]/n.CPUCap + n.Metrics["mem_used"]/n.MemCap)
         scores[n.ID] = s.W.Power*power + s.W.SCI*sci + s.W.Util*util
     }
@@ -0,0 +1,24 @@
+package energyvis
+
+import (
+	"kube-scheduler/pkg/core"
+	"kube-scheduler/pkg/metrics"
+)
+
+func init() {
+	core.RegisterPolicy(core.PolicyFactory{
+		Name: "energyvis",
+		Doc:  "node power, predicted job carbon and utilisation",
+		Params: []core.ParamSpec{
+			{Name: "power", Kind: core.ParamFloat, Default: "1.0", Doc: "weight of the node draw (W)"},
+			{Name: "sci", Kind: core.ParamFloat, Default: "1.0", Doc: "weight of the predicted mgCO₂"},
+			{Name: "util", Kind: core.ParamFloat, Default: "0.2", Doc: "weight of CPU+memory utilisation"},
+		},
+		CIWeight: "sci",
+		New: func(p core.Params) (core.Policy, error) {
+			s := &EnergyVis{}
+			s.W.Power, s.W.SCI, s.W.Util = p.Float("power"), p.Float("sci"), p.Float("util")
+			return core.SchedulerPolicy{Scheduler: s, View: metrics.NodeView}, nil
+		},
+	})
+}
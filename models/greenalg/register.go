@@ -0,0 +1,24 @@
+package greenalg
+
+import (
+	"kube-scheduler/pkg/core"
+	"kube-scheduler/pkg/metrics"
+)
+
+func init() {
+	core.RegisterPolicy(core.PolicyFactory{
+		Name: "greenalg",
+		Doc:  "Green Algorithms: carbon per second of runtime, duration and energy",
+		Params: []core.ParamSpec{
+			{Name: "ci", Kind: core.ParamFloat, Default: "1.0", Doc: "weight of gCO₂ per second"},
+			{Name: "dur", Kind: core.ParamFloat, Default: "0.2", Doc: "weight of the predicted duration (s)"},
+			{Name: "energy", Kind: core.ParamFloat, Default: "0.5", Doc: "weight of the predicted energy (kWh)"},
+		},
+		CIWeight: "ci",
+		New: func(p core.Params) (core.Policy, error) {
+			s := &GreenAlgorithms{}
+			s.W.CI, s.W.Dur, s.W.Energy = p.Float("ci"), p.Float("dur"), p.Float("energy")
+			return core.SchedulerPolicy{Scheduler: s, View: metrics.NodeView}, nil
+		},
+	})
+}
@@ -0,0 +1,11 @@
+package k8sched
+
+import "kube-scheduler/pkg/core"
+
+func init() {
+	core.RegisterPolicy(core.PolicyFactory{
+		Name: "k8",
+		Doc:  "Kubernetes-style least-allocated baseline (carbon-unaware)",
+		New:  func(core.Params) (core.Policy, error) { return &Policy{}, nil },
+	})
+}
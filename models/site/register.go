@@ -0,0 +1,42 @@
+package site
+
+import (
+	"strings"
+
+	"kube-scheduler/pkg/core"
+)
+
+func init() {
+	core.RegisterPolicy(core.PolicyFactory{
+		Name: "site",
+		Doc:  "two-level site-then-node weighted sum (carbon, wait, queue, price, repro)",
+		Params: []core.ParamSpec{
+			{Name: "carbon", Kind: core.ParamFloat, Default: "1.0", Doc: "weight of the job's gCO₂"},
+			{Name: "wait", Kind: core.ParamFloat, Default: "0.2", Doc: "weight of the estimated start delay (s)"},
+			{Name: "queue", Kind: core.ParamFloat, Default: "0", Doc: "weight of the site's running reservations"},
+			{Name: "price", Kind: core.ParamFloat, Default: "0", Doc: "weight of the job's electricity cost (€)"},
+			{Name: "repro", Kind: core.ParamFloat, Default: "0", Doc: "weight of the reproducibility penalty"},
+			{Name: "repro_keys", Kind: core.ParamString, Default: "site_id", Doc: "job labels a node must match (k|k), penalised once per mismatch"},
+		},
+		CIWeight: "carbon",
+		New: func(p core.Params) (core.Policy, error) {
+			pol := New(core.SiteWeights{
+				Carbon: p.Float("carbon"),
+				Wait:   p.Float("wait"),
+				Queue:  p.Float("queue"),
+				Price:  p.Float("price"),
+				Repro:  p.Float("repro"),
+			})
+			var keys []string
+			for _, k := range strings.Split(p.String("repro_keys"), "|") {
+				if k = strings.TrimSpace(k); k != "" {
+					keys = append(keys, k)
+				}
+			}
+			if len(keys) > 0 {
+				pol.Sched.Repro = core.LabelRepro{Keys: keys}
+			}
+			return pol, nil
+		},
+	})
+}
@@ -0,0 +1,53 @@
+// Package site runs the two-level core.SiteScheduler in BaseSim, with
+// per-site forecasts and queue state built from the simulated nodes.
+package site
+
+import (
+	"kube-scheduler/pkg/core"
+	"kube-scheduler/pkg/forecast"
+)
+
+type Policy struct {
+	core.SchedulerPolicy
+	Sched    *core.SiteScheduler
+	Forecast forecast.Forecaster
+
+	prov *forecast.Provider // bound by BindNodes
+}
+
+func New(w core.SiteWeights) *Policy {
+	s := &core.SiteScheduler{W: w, Energy: core.LinearEnergy{}}
+	return &Policy{SchedulerPolicy: core.SchedulerPolicy{Scheduler: s}, Sched: s}
+}
+
+// SetForecaster implements forecast.Aware. It may be called before or after
+// BindNodes.
+func (p *Policy) SetForecaster(f forecast.Forecaster) {
+	p.Forecast = f
+	if p.prov != nil {
+		p.prov.F = f
+	}
+}
+
+// BindNodes implements core.NodeBinder: forecasts are keyed by site ID (the
+// site's grid trace) and node name, queue state comes from the nodes and
+// prices from the site tariffs.
+func (p *Policy) BindNodes(nodes []*core.SimulatedNode) {
+	prov := &forecast.Provider{F: p.Forecast, Series: map[string]forecast.Series{}}
+	for _, n := range nodes {
+		prov.Series[n.Name] = forecast.NodeSeries(n)
+		if n.Site != nil && n.Site.ActiveCI() != nil {
+			prov.Series[n.SiteID] = n.Site.ActiveCI().At
+		}
+	}
+	p.prov = prov
+	p.Sched.CI = prov
+	p.Sched.Queue = &core.SimQueue{Nodes: nodes}
+	prices := core.SitePrice{}
+	for _, n := range nodes {
+		if n.Site != nil && n.Site.Price != nil {
+			prices[n.SiteID] = n.Site.Price
+		}
+	}
+	p.Sched.Price = prices
+}
@@ -0,0 +1,255 @@
+// Package calibrate fits node power models to metrics collected on the
+// Kubernetes testbed: the CSVs written by the central unit's /metrics-ingest
+// (metrics_aggregated.csv) and /metrics-export-range
+// (full_scaphandre_metrics.csv). Both are wide files with a timestamp
+// column and one column per series, named either by metric or by the full
+// Prometheus series (`scaph_host_power_microwatts{node="n1"}`).
+package calibrate
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"kube-scheduler/pkg/core"
+)
+
+// Default metric names: Scaphandre's host power and the compute node's CPU
+// usage gauge (percent).
+const (
+	DefaultPowerMetric = "scaph_host_power_microwatts"
+	DefaultUtilMetric  = "compute_node_cpu_usage"
+)
+
+// hostLabels are the series labels that identify a host, in order of
+// preference.
+var hostLabels = []string{"node", "nodename", "hostname", "host", "instance"}
+
+var seriesRE = regexp.MustCompile(`^([a-zA-Z_:][a-zA-Z0-9_:]*)(?:\{(.*)\})?$`)
+var labelRE = regexp.MustCompile(`(\w+?)="(.*?)"`)
+
+// Sample is one simultaneous (utilisation, power) observation of a host.
+type Sample struct {
+	Host   string
+	Util   float64 // 0..1
+	PowerW float64
+}
+
+// Options select the columns to pair.
+type Options struct {
+	PowerMetric string  // default DefaultPowerMetric; *_microwatts are converted to W
+	UtilMetric  string  // default DefaultUtilMetric
+	UtilScale   float64 // divide utilisation by this (e.g. cores for a load average); 0 = auto (percent if any value > 1)
+	Host        string  // host of series without a host label
+}
+
+// Read extracts the samples of one CSV. Rows where a host lacks either value
+// are skipped.
+func Read(r io.Reader, o Options) ([]Sample, error) {
+	if o.PowerMetric == "" {
+		o.PowerMetric = DefaultPowerMetric
+	}
+	if o.UtilMetric == "" {
+		o.UtilMetric = DefaultUtilMetric
+	}
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1 // the ingest CSV keeps its first header as series come and go
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+
+	type cols struct{ power, util int }
+	byHost := map[string]*cols{}
+	for i, h := range header {
+		m := seriesRE.FindStringSubmatch(strings.TrimSpace(h))
+		if m == nil || (m[1] != o.PowerMetric && m[1] != o.UtilMetric) {
+			continue
+		}
+		host := hostOf(m[2], o.Host)
+		c := byHost[host]
+		if c == nil {
+			c = &cols{-1, -1}
+			byHost[host] = c
+		}
+		if m[1] == o.PowerMetric {
+			c.power = i
+		} else {
+			c.util = i
+		}
+	}
+	hosts := make([]string, 0, len(byHost))
+	for h, c := range byHost {
+		if c.power >= 0 && c.util >= 0 {
+			hosts = append(hosts, h)
+		}
+	}
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("no host has both %s and %s columns", o.PowerMetric, o.UtilMetric)
+	}
+	sort.Strings(hosts)
+
+	powerScale := 1.0
+	if strings.HasSuffix(o.PowerMetric, "_microwatts") {
+		powerScale = 1e-6
+	}
+	var out []Sample
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range hosts {
+			c := byHost[h]
+			if c.power >= len(rec) || c.util >= len(rec) {
+				continue
+			}
+			p, err1 := strconv.ParseFloat(strings.TrimSpace(rec[c.power]), 64)
+			u, err2 := strconv.ParseFloat(strings.TrimSpace(rec[c.util]), 64)
+			if err1 != nil || err2 != nil || math.IsNaN(p) || math.IsNaN(u) {
+				continue
+			}
+			out = append(out, Sample{Host: h, Util: u, PowerW: p * powerScale})
+		}
+	}
+
+	scale := o.UtilScale
+	if scale <= 0 {
+		scale = 1
+		for _, s := range out {
+			if s.Util > 1 {
+				scale = 100
+				break
+			}
+		}
+	}
+	for i := range out {
+		out[i].Util = math.Max(0, math.Min(1, out[i].Util/scale))
+	}
+	return out, nil
+}
+
+func hostOf(labels, def string) string {
+	kv := map[string]string{}
+	for _, m := range labelRE.FindAllStringSubmatch(labels, -1) {
+		kv[m[1]] = m[2]
+	}
+	for _, l := range hostLabels {
+		if v := kv[l]; v != "" {
+			return v
+		}
+	}
+	return def
+}
+
+// Fit is the power model fitted to one host's samples.
+type Fit struct {
+	Host   string
+	N      int
+	Linear core.LinearPower // least-squares line, evaluated at 0 and 100%
+	R2     float64          // of the linear fit
+	RMSE   float64          // of the linear fit (W)
+	Curve  *core.PowerCurve // binned means; nil unless FitHost was given bins
+}
+
+// Model returns the curve when fitted, else the linear model.
+func (f Fit) Model() core.PowerModel {
+	if f.Curve != nil {
+		return f.Curve
+	}
+	return f.Linear
+}
+
+// FitAll groups samples by host and fits each (see FitHost), in host order.
+func FitAll(samples []Sample, bins int) []Fit {
+	byHost := map[string][]Sample{}
+	for _, s := range samples {
+		byHost[s.Host] = append(byHost[s.Host], s)
+	}
+	hosts := make([]string, 0, len(byHost))
+	for h := range byHost {
+		hosts = append(hosts, h)
+	}
+	sort.Strings(hosts)
+	out := make([]Fit, 0, len(hosts))
+	for _, h := range hosts {
+		out = append(out, FitHost(h, byHost[h], bins))
+	}
+	return out
+}
+
+// FitHost fits power = idle + (peak-idle)·util by least squares. With bins
+// > 0 it also builds a piecewise curve from the mean utilisation and power
+// of each non-empty equal-width utilisation bin, anchored at 0 and 100% by
+// the linear fit where the samples do not reach them.
+func FitHost(host string, samples []Sample, bins int) Fit {
+	f := Fit{Host: host, N: len(samples)}
+	if len(samples) == 0 {
+		return f
+	}
+	var su, sp, suu, sup float64
+	for _, s := range samples {
+		su += s.Util
+		sp += s.PowerW
+		suu += s.Util * s.Util
+		sup += s.Util * s.PowerW
+	}
+	n := float64(len(samples))
+	mu, mp := su/n, sp/n
+	slope := 0.0
+	if v := suu/n - mu*mu; v > 1e-12 {
+		slope = (sup/n - mu*mp) / v
+	}
+	idle := mp - slope*mu
+	f.Linear = core.LinearPower{IdleW: idle, PeakW: idle + slope}
+
+	var ssRes, ssTot float64
+	for _, s := range samples {
+		r := s.PowerW - (idle + slope*s.Util)
+		ssRes += r * r
+		ssTot += (s.PowerW - mp) * (s.PowerW - mp)
+	}
+	f.RMSE = math.Sqrt(ssRes / n)
+	f.R2 = 1
+	if ssTot > 0 {
+		f.R2 = 1 - ssRes/ssTot
+	}
+
+	if bins > 0 {
+		sumU := make([]float64, bins)
+		sumP := make([]float64, bins)
+		cnt := make([]int, bins)
+		for _, s := range samples {
+			b := int(s.Util * float64(bins))
+			if b >= bins {
+				b = bins - 1
+			}
+			sumU[b] += s.Util
+			sumP[b] += s.PowerW
+			cnt[b]++
+		}
+		var us, ws []float64
+		for b := range cnt {
+			if cnt[b] > 0 {
+				us = append(us, sumU[b]/float64(cnt[b]))
+				ws = append(ws, sumP[b]/float64(cnt[b]))
+			}
+		}
+		if us[0] > 0 {
+			us, ws = append([]float64{0}, us...), append([]float64{f.Linear.Power(0)}, ws...)
+		}
+		if us[len(us)-1] < 1 {
+			us, ws = append(us, 1), append(ws, f.Linear.Power(1))
+		}
+		f.Curve, _ = core.NewPowerCurve(us, ws)
+	}
+	return f
+}
@@ -0,0 +1,99 @@
+package calibrate
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"kube-scheduler/pkg/core"
+)
+
+// n1 draws 100 + 200·util W; n2 lacks a value in the second row and its
+// utilisation series carries more labels than the host.
+const scaphCSV = `"timestamp","scaph_host_power_microwatts{node=""n1""}","compute_node_cpu_usage{node=""n1""}","scaph_host_power_microwatts{node=""n2""}","compute_node_cpu_usage{instance=""10.0.0.2:9100"",node=""n2""}","scaph_process_power_consumption_microwatts{node=""n1""}"
+1767225600,140000000,20,150000000,10,5
+1767225660,180000000,40,,30,5
+1767225720,220000000,60,250000000,60,5
+1767225780,260000000,80,300000000,100,5
+`
+
+func TestRead(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		csv  string
+		o    Options
+		want []Sample
+	}{
+		{"series headers, µW and percent", scaphCSV, Options{}, []Sample{
+			{"n1", 0.2, 140}, {"n2", 0.1, 150},
+			{"n1", 0.4, 180},
+			{"n1", 0.6, 220}, {"n2", 0.6, 250},
+			{"n1", 0.8, 260}, {"n2", 1, 300},
+		}},
+		{"fractions are not rescaled", "timestamp,power_watts,cpu\n0,120,0.1\n60,200,0.5\n", Options{PowerMetric: "power_watts", UtilMetric: "cpu", Host: "h"},
+			[]Sample{{"h", 0.1, 120}, {"h", 0.5, 200}}},
+		{"explicit scale, clamped", "timestamp,power_watts,load\n0,120,2\n60,200,12\n", Options{PowerMetric: "power_watts", UtilMetric: "load", UtilScale: 8, Host: "h"},
+			[]Sample{{"h", 0.25, 120}, {"h", 1, 200}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Read(strings.NewReader(tc.csv), tc.o)
+			if err != nil {
+				t.Fatalf("Read: %v", err)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("read %v, want %v", got, tc.want)
+			}
+			for i := range got {
+				if got[i].Host != tc.want[i].Host || math.Abs(got[i].Util-tc.want[i].Util) > 1e-9 || math.Abs(got[i].PowerW-tc.want[i].PowerW) > 1e-6 {
+					t.Errorf("sample %d = %+v, want %+v", i, got[i], tc.want[i])
+				}
+			}
+		})
+	}
+	if _, err := Read(strings.NewReader("timestamp,cpu\n0,1\n"), Options{UtilMetric: "cpu"}); err == nil {
+		t.Errorf("Read without a power column: no error")
+	}
+}
+
+func TestFitHost(t *testing.T) {
+	samples, err := Read(strings.NewReader(scaphCSV), Options{})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	fits := FitAll(samples, 5)
+	if len(fits) != 2 || fits[0].Host != "n1" || fits[1].Host != "n2" {
+		t.Fatalf("fitted %+v, want n1 and n2", fits)
+	}
+	n1 := fits[0]
+	if n1.N != 4 || math.Abs(n1.Linear.IdleW-100) > 1e-6 || math.Abs(n1.Linear.PeakW-300) > 1e-6 || math.Abs(n1.R2-1) > 1e-9 || n1.RMSE > 1e-6 {
+		t.Errorf("n1 fit %+v, want 4 samples, idle 100 W, peak 300 W, R² 1", n1)
+	}
+	// utilisation 20..80% fills bins 1..4 of 5; 0 and 100% come from the line
+	const want = "piecewise:0=100:0.2=140:0.4=180:0.6=220:0.8=260:1=300"
+	if got := n1.Curve.String(); got != want {
+		t.Fatalf("curve %s, want %s", got, want)
+	}
+	m, err := core.ParsePowerModel(want)
+	if err != nil {
+		t.Fatalf("ParsePowerModel(%s): %v", want, err)
+	}
+	for _, u := range []float64{0, 0.1, 0.5, 0.9, 1} {
+		if got, w := m.Power(u), n1.Linear.Power(u); math.Abs(got-w) > 1e-9 {
+			t.Errorf("parsed curve at %g: %g W, want %g W", u, got, w)
+		}
+	}
+
+	// Not a line: 100, 100, 200 W at 0, 50, 100%.
+	f := FitHost("h", []Sample{{"h", 0, 100}, {"h", 0.5, 100}, {"h", 1, 200}}, 2)
+	if math.Abs(f.Linear.IdleW-250.0/3) > 1e-9 || math.Abs(f.Linear.PeakW-550.0/3) > 1e-9 || math.Abs(f.R2-0.75) > 1e-9 {
+		t.Errorf("fit %+v, want idle 83.3 W, peak 183.3 W, R² 0.75", f)
+	}
+	// the first bin starts at 0; the last bin's mean (75%) is anchored at
+	// 100% by the line
+	if got, want := f.Curve.String(), "piecewise:0=100:0.75=150:1=183.3"; got != want {
+		t.Errorf("curve %s, want %s", got, want)
+	}
+	if f.Model() != core.PowerModel(f.Curve) {
+		t.Errorf("Model is not the curve")
+	}
+}
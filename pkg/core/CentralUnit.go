@@ -11,6 +11,14 @@ import (
 type CentralUnit struct {
 	Clusters []Cluster
 	Strategy SchedulingStrategy
+	Clock    Clock // nil means WallClock
+}
+
+func (cu CentralUnit) now() time.Time {
+	if cu.Clock == nil {
+		return time.Now()
+	}
+	return cu.Clock.Now()
 }
 
 type SchedulingDecision struct {
@@ -18,7 +26,7 @@ type SchedulingDecision struct {
 	StrategyName    string
 	SelectedCluster string
 	EstimatedCost   float64
-	SCI_kWh         float64
+	CIgPerKWh       float64 // carbon intensity of the selected cluster
 	Timestamp       time.Time
 	Reasoning       string
 }
@@ -49,8 +57,8 @@ func (cu CentralUnit) Dispatch(workloads []WorkloadTestbed) {
 			StrategyName:    reflect.TypeOf(cu.Strategy).Name(),
 			SelectedCluster: selected.Name(),
 			EstimatedCost:   selected.EstimateEnergyCost(w),
-			SCI_kWh:         selected.CarbonIntensity(),
-			Timestamp:       time.Now(),
+			CIgPerKWh:       selected.CarbonIntensity(),
+			Timestamp:       cu.now(),
 			Reasoning:       reason,
 		}
 		decisionLog = append(decisionLog, decision)
@@ -72,8 +80,8 @@ func (cu CentralUnit) DispatchAll(workloads []WorkloadTestbed) {
 				StrategyName:    reflect.TypeOf(strategy).Name(),
 				SelectedCluster: selected.Name(),
 				EstimatedCost:   selected.EstimateEnergyCost(w),
-				SCI_kWh:         selected.CarbonIntensity(),
-				Timestamp:       time.Now(),
+				CIgPerKWh:       selected.CarbonIntensity(),
+				Timestamp:       cu.now(),
 				Reasoning:       reason,
 			}
 			decisionLog = append(decisionLog, decision)
@@ -83,10 +91,10 @@ func (cu CentralUnit) DispatchAll(workloads []WorkloadTestbed) {
 
 func PrintDecisionTable() {
 	fmt.Println("\n================= Scheduling Decision Summary =================")
-	fmt.Printf("%-12s %-22s %-16s %-10s %-8s %-10s\n", "Workload", "Strategy", "Cluster", "Cost", "SCI", "Reason")
+	fmt.Printf("%-12s %-22s %-16s %-10s %-8s %-10s\n", "Workload", "Strategy", "Cluster", "Cost", "CI", "Reason")
 	for _, d := range decisionLog {
 		fmt.Printf("%-12s %-22s %-16s %-10.2f %-8.1f %-10s\n",
-			d.WorkloadID, d.StrategyName, d.SelectedCluster, d.EstimatedCost, d.SCI_kWh, d.Reasoning)
+			d.WorkloadID, d.StrategyName, d.SelectedCluster, d.EstimatedCost, d.CIgPerKWh, d.Reasoning)
 	}
 }
 
@@ -6,9 +6,28 @@ type Job struct {
 	ID                string
 	CPUReq            float64
 	MemReq            float64
-	DeadlineMs        int64
+	DeadlineMs        int64 // completion deadline relative to SubmitAt; 0 = none
 	Tags              map[string]string
 	EstimatedDuration float64
-	Labels		   map[string]string
-	SubmitAt		   time.Time
-}
\ No newline at end of file
+	Labels            map[string]string
+	SubmitAt          time.Time
+}
+
+// Deadline returns the absolute completion deadline, or the zero time if the
+// job has none.
+func (j Job) Deadline() time.Time {
+	if j.DeadlineMs <= 0 {
+		return time.Time{}
+	}
+	return j.SubmitAt.Add(time.Duration(j.DeadlineMs) * time.Millisecond)
+}
+
+// LatestStart is the last start time that still meets the deadline given the
+// estimated duration (zero if the job has no deadline).
+func (j Job) LatestStart() time.Time {
+	d := j.Deadline()
+	if d.IsZero() {
+		return d
+	}
+	return d.Add(-time.Duration(j.EstimatedDuration * float64(time.Second)))
+}
@@ -14,6 +14,18 @@ type RemoteCluster struct {
 	NameKey string `json:"name"`
 	MetricsURL  string `json:"metrics_url"`
 	SubmitURL string `json:"submit_url"`
+	PriceEURPerKWh float64 `json:"price_eur_per_kwh,omitempty"` // electricity tariff; 0 → DefaultPriceEURPerKWh
+}
+
+// DefaultPriceEURPerKWh is the tariff assumed for clusters without one.
+const DefaultPriceEURPerKWh = 0.18
+
+// Price returns the cluster's electricity price (€/kWh).
+func (c RemoteCluster) Price() float64 {
+	if c.PriceEURPerKWh > 0 {
+		return c.PriceEURPerKWh
+	}
+	return DefaultPriceEURPerKWh
 }
 
 
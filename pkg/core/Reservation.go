@@ -3,7 +3,17 @@ package core
 import "time"
 
 type Reservation struct {
-	End time.Time
-	CPU float64
-	Mem float64
+	JobID    string
+	End      time.Time
+	Estimate time.Time // End as the scheduler predicted it (zero = End)
+	CPU      float64
+	Mem      float64
+}
+
+// EstimatedEnd is Estimate, or End if the reservation has none.
+func (r Reservation) EstimatedEnd() time.Time {
+	if r.Estimate.IsZero() {
+		return r.End
+	}
+	return r.Estimate
 }
\ No newline at end of file
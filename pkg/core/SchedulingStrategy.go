@@ -132,7 +132,7 @@ func (s CIawareStrategy) SelectCluster(clusters []Cluster, w WorkloadTestbed) (C
 		}
 
 		ci := rc.CarbonIntensity()               // Default 300.0 gCO₂/kWh
-		price := 0.18                            // Example static price per kWh
+		price := rc.Price()                      // Cluster tariff (€/kWh)
 		power := 150.0                           // Static power draw in Watts
 		duration := 10.0                         // Estimated job duration in seconds
 		overhead := 5.0                          // Overhead in seconds
@@ -6,7 +6,7 @@ type SimulatedCluster struct {
 	ClusterName string
 	MaxCPU      int
 	EnergyBias  float64
-	SCI_kWh     float64
+	CIgPerKWh   float64 // grid carbon intensity (gCO₂/kWh)
 	CurrentLoad float64
 	Location    string
 }
@@ -17,8 +17,8 @@ func (c SimulatedCluster) EstimateEnergyCost(w WorkloadTestbed) float64 {
 	return float64(w.CPURequirement) * c.EnergyBias
 }
 func (c SimulatedCluster) SubmitJob(w WorkloadTestbed) error {
-	fmt.Printf("[Cluster %s] Job %s submitted (CPU: %d, EnergyBias: %.2f, SCI: %.1f)\n",
-		c.ClusterName, w.ID, w.CPURequirement, c.EnergyBias, c.SCI_kWh)
+	fmt.Printf("[Cluster %s] Job %s submitted (CPU: %d, EnergyBias: %.2f, CI: %.1f)\n",
+		c.ClusterName, w.ID, w.CPURequirement, c.EnergyBias, c.CIgPerKWh)
 	return nil
 }
-func (c SimulatedCluster) CarbonIntensity() float64 { return c.SCI_kWh }
\ No newline at end of file
+func (c SimulatedCluster) CarbonIntensity() float64 { return c.CIgPerKWh }
\ No newline at end of file
@@ -13,8 +13,12 @@ type SimulatedNode struct {
 	AvailableCPU    float64
 	AvailableMemory float64
 	CarbonIntensity float64        // gCO₂/kWh (optional, if we have traces keep it)
+	CISeries        Series         // time-varying CI (seeded randwalk/ou); overrides ci_profile
 	Labels          map[string]string
 	Metadata        map[string]string
+	Power           PowerModel     // draw vs. utilisation; nil → linear around peak_power_w
+	EmbodiedKg      float64        // manufacturing emissions (kgCO₂e) over the node's lifetime
+	Lifetime        time.Duration  // expected lifespan; 0 → DefaultLifetime
 
 	Reservations    []Reservation
 	SiteID		 string
@@ -35,6 +39,50 @@ func NewNode(name string, cpu, mem, ci float64) *SimulatedNode {
 	}
 }
 
+// Clone returns an independent copy of the node's mutable state (capacity,
+// reservations, labels, metadata). Site, CISeries and Power are shared, as
+// they are read-only during a run.
+func (n *SimulatedNode) Clone() *SimulatedNode {
+	c := *n
+	c.Labels = make(map[string]string, len(n.Labels))
+	for k, v := range n.Labels {
+		c.Labels[k] = v
+	}
+	c.Metadata = make(map[string]string, len(n.Metadata))
+	for k, v := range n.Metadata {
+		c.Metadata[k] = v
+	}
+	c.Reservations = append(make([]Reservation, 0, cap(n.Reservations)), n.Reservations...)
+	return &c
+}
+
+// CloneNodes clones every node (see Clone).
+func CloneNodes(nodes []*SimulatedNode) []*SimulatedNode {
+	out := make([]*SimulatedNode, len(nodes))
+	for i, n := range nodes {
+		out[i] = n.Clone()
+	}
+	return out
+}
+
+// DefaultLifetime is the hardware lifespan embodied emissions are amortised
+// over when a node does not set one.
+const DefaultLifetime = 4 * 365 * 24 * time.Hour
+
+// EmbodiedG is the share of the node's embodied emissions (gCO₂e) charged to
+// a reservation of cpu cores for d, as in the Green Software Foundation SCI:
+// M = TE × (d / lifetime) × (cpu / TotalCPU).
+func (n *SimulatedNode) EmbodiedG(cpu float64, d time.Duration) float64 {
+	if n.EmbodiedKg <= 0 || n.TotalCPU <= 0 || d <= 0 {
+		return 0
+	}
+	life := n.Lifetime
+	if life <= 0 {
+		life = DefaultLifetime
+	}
+	return n.EmbodiedKg * 1000 * (d.Seconds() / life.Seconds()) * math.Min(cpu/n.TotalCPU, 1)
+}
+
 func (n *SimulatedNode) CanAccept(w Workload) bool {
 	return n.AvailableCPU >= w.CPU && n.AvailableMemory >= w.Memory
 }
@@ -43,12 +91,27 @@ func (n *SimulatedNode) Reserve(w Workload, start time.Time) {
 	n.AvailableCPU -= w.CPU
 	n.AvailableMemory -= w.Memory
 	n.Reservations = append(n.Reservations, Reservation{
-		End: start.Add(w.Duration),
-		CPU: w.CPU,
-		Mem: w.Memory,
+		JobID: w.ID,
+		End:   start.Add(w.Duration),
+		CPU:   w.CPU,
+		Mem:   w.Memory,
 	})
 }
 
+// Evict releases the reservation of jobID before its end (a preempted
+// job), reporting whether it was found.
+func (n *SimulatedNode) Evict(jobID string) bool {
+	for i, r := range n.Reservations {
+		if r.JobID == jobID {
+			n.AvailableCPU = math.Min(n.AvailableCPU+r.CPU, n.TotalCPU)
+			n.AvailableMemory = math.Min(n.AvailableMemory+r.Mem, n.TotalMemory)
+			n.Reservations = append(n.Reservations[:i], n.Reservations[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
 // Release resources for all reservations ending <= t
 func (n *SimulatedNode) Release(t time.Time) {
 	out := n.Reservations[:0]
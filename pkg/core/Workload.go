@@ -1,6 +1,7 @@
 package core
 
 import (
+	"strconv"
 	"time"
 )
 
@@ -13,6 +14,30 @@ type Workload struct {
 	Memory     float64
 	Tag		 string
 	Labels	 map[string]string
+	Deadline   time.Duration // must finish by SubmitTime+Deadline; 0 = none
+}
+
+// Preemptible reports whether the workload is labelled preemptible=true,
+// i.e. it can be checkpointed, suspended and resumed (see Preemption).
+func (w Workload) Preemptible() bool {
+	v, _ := strconv.ParseBool(w.Labels["preemptible"])
+	return v
+}
+
+// Priority is the workload's "priority" label (0 when absent); higher
+// priorities may preempt lower ones.
+func (w Workload) Priority() int {
+	p, _ := strconv.Atoi(w.Labels["priority"])
+	return p
+}
+
+// Tenant is the group the workload is accounted to for fair share: its
+// "tenant" label, else its Tag.
+func (w Workload) Tenant() string {
+	if t, ok := w.Labels["tenant"]; ok {
+		return t
+	}
+	return w.Tag
 }
 
 type WorkloadTestbed struct {
@@ -1,12 +1,79 @@
 package core
 
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
 func JobView(w Workload) Job {
-	return Job{
+	j := Job{
 		ID:                w.ID,
 		CPUReq:            w.CPU,
 		MemReq:            w.Memory,
 		EstimatedDuration: w.Duration.Seconds(),
 		Labels:            w.Labels,
 		SubmitAt:          w.SubmitTime,
+		DeadlineMs:        w.Deadline.Milliseconds(),
+	}
+	if w.Tag != "" {
+		j.Tags = map[string]string{"tag": w.Tag}
+	}
+	return j
+}
+
+// NodeView converts a simulated node into the Node a Scheduler scores.
+// The ID is the node name (the key BaseSim matches scores against) and
+// Metrics carry cpu_used, mem_used, ci_g_per_kwh and peak_power_w; Power is
+// the node's PowerModel.
+func NodeView(n *SimulatedNode) Node {
+	m := map[string]float64{
+		"cpu_used":     n.TotalCPU - n.AvailableCPU,
+		"mem_used":     n.TotalMemory - n.AvailableMemory,
+		"ci_g_per_kwh": n.CarbonIntensity,
+	}
+	if p, err := strconv.ParseFloat(n.Metadata["peak_power_w"], 64); err == nil && p > 0 {
+		m["peak_power_w"] = p
+	}
+	id := n.Name
+	if id == "" {
+		id = n.ID
+	}
+	return Node{
+		ID:      id,
+		CPUCap:  n.TotalCPU,
+		MemCap:  n.TotalMemory,
+		Metrics: m,
+		Labels:  n.Labels,
+		SiteID:  n.SiteID,
+		Site:    n.Site,
+		Power:   n.PowerModel(),
+	}
+}
+
+// SchedulerPolicy runs a Scheduler (scored over []Node) as a BaseSim Policy.
+// Nodes that cannot take the job right now are left out, since Node-based
+// schedulers score every node they are given. View, if set, replaces
+// NodeView, e.g. metrics.NodeView to fill CI and energy predictions.
+type SchedulerPolicy struct {
+	Scheduler
+	View func(n *SimulatedNode, j Job, now time.Time) Node
+}
+
+func (p SchedulerPolicy) Score(ctx context.Context, j Job, nodes []SimulatedNode) (Scores, error) {
+	now := Now(ctx)
+	w := Workload{CPU: j.CPUReq, Memory: j.MemReq}
+	out := make([]Node, 0, len(nodes))
+	for i := range nodes {
+		n := &nodes[i]
+		if !n.CanAccept(w) {
+			continue
+		}
+		if p.View != nil {
+			out = append(out, p.View(n, j, now))
+		} else {
+			out = append(out, NodeView(n))
+		}
 	}
-}
\ No newline at end of file
+	return p.Scheduler.Score(ctx, j, out)
+}
@@ -0,0 +1,210 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// QueueDiscipline is the order in which BaseSim starts queued jobs.
+type QueueDiscipline int
+
+const (
+	// QueueFirstFit starts every queued job that fits, in queue order, so
+	// small jobs can overtake a large one indefinitely (the default).
+	QueueFirstFit QueueDiscipline = iota
+	// QueueFCFS starts jobs strictly in queue order: a pass ends at the
+	// first job that does not fit.
+	QueueFCFS
+	// QueueEASY reserves the earliest start of the first job that does not
+	// fit and lets later jobs start only if, by their runtime estimate, they
+	// do not delay it (EASY backfilling).
+	QueueEASY
+	// QueueConservative reserves a start for every job that does not fit,
+	// in queue order, and lets later jobs start only if they delay none of
+	// the reservations (conservative backfilling).
+	QueueConservative
+)
+
+var disciplineNames = []string{"first_fit", "fcfs", "easy", "conservative"}
+
+func (q QueueDiscipline) String() string {
+	if int(q) < len(disciplineNames) {
+		return disciplineNames[q]
+	}
+	return fmt.Sprintf("QueueDiscipline(%d)", int(q))
+}
+
+// ParseQueueDiscipline reads first_fit (or ""), fcfs, easy or conservative.
+func ParseQueueDiscipline(s string) (QueueDiscipline, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return QueueFirstFit, nil
+	}
+	for i, name := range disciplineNames {
+		if s == name {
+			return QueueDiscipline(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown queue discipline %q (want %s)", s, strings.Join(disciplineNames, ", "))
+}
+
+// step is a breakpoint of an availability profile: cpu and mem are free
+// from t until the next breakpoint.
+type step struct {
+	t        time.Time
+	cpu, mem float64
+}
+
+// profile is a node's estimated free capacity over time, from now on.
+type profile []step
+
+// profileOf builds n's profile at now from its reservations, ending each at
+// its estimate. Jobs running past their estimate are expected to end now.
+func profileOf(n *SimulatedNode, now time.Time) profile {
+	p := profile{{t: now, cpu: n.AvailableCPU, mem: n.AvailableMemory}}
+	rs := append([]Reservation(nil), n.Reservations...)
+	sort.Slice(rs, func(i, j int) bool { return rs[i].EstimatedEnd().Before(rs[j].EstimatedEnd()) })
+	for _, r := range rs {
+		end := r.EstimatedEnd()
+		if end.Before(now) {
+			end = now
+		}
+		last := &p[len(p)-1]
+		if !end.After(last.t) {
+			last.cpu += r.CPU
+			last.mem += r.Mem
+			continue
+		}
+		p = append(p, step{t: end, cpu: last.cpu + r.CPU, mem: last.mem + r.Mem})
+	}
+	return p
+}
+
+// fits reports whether cpu and mem stay free over [t, t+d).
+func (p profile) fits(t time.Time, d time.Duration, cpu, mem float64) bool {
+	end := t.Add(d)
+	for i, s := range p {
+		if i+1 < len(p) && !p[i+1].t.After(t) {
+			continue // ends before t
+		}
+		if !s.t.Before(end) {
+			break
+		}
+		if s.cpu < cpu || s.mem < mem {
+			return false
+		}
+	}
+	return true
+}
+
+// earliest returns the first breakpoint from which cpu and mem stay free
+// for d (ok=false if never).
+func (p profile) earliest(d time.Duration, cpu, mem float64) (time.Time, bool) {
+	from := 0 // first breakpoint of the current run of steps with room
+	for i, s := range p {
+		if s.cpu < cpu || s.mem < mem {
+			from = i + 1
+			continue
+		}
+		if i+1 == len(p) || !p[i+1].t.Before(p[from].t.Add(d)) {
+			return p[from].t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// reserve takes cpu and mem over [t, t+d).
+func (p *profile) reserve(t time.Time, d time.Duration, cpu, mem float64) {
+	end := t.Add(d)
+	p.split(t)
+	p.split(end)
+	for i := range *p {
+		s := &(*p)[i]
+		if !s.t.Before(t) && s.t.Before(end) {
+			s.cpu -= cpu
+			s.mem -= mem
+		}
+	}
+}
+
+// split inserts a breakpoint at t carrying the capacity free just before it.
+func (p *profile) split(t time.Time) {
+	i := sort.Search(len(*p), func(i int) bool { return !(*p)[i].t.Before(t) })
+	if i == 0 || (i < len(*p) && (*p)[i].t.Equal(t)) {
+		return
+	}
+	s := (*p)[i-1]
+	s.t = t
+	*p = append(*p, step{})
+	copy((*p)[i+1:], (*p)[i:])
+	(*p)[i] = s
+}
+
+// backfill is the state of one EASY or conservative scheduling pass: the
+// profile of every node, with the reservations made so far.
+type backfill struct {
+	b        *BaseSim
+	profiles map[*SimulatedNode]*profile
+	reserved int
+}
+
+func (b *BaseSim) newBackfill() *backfill {
+	bf := &backfill{b: b, profiles: make(map[*SimulatedNode]*profile, len(b.Nodes))}
+	for _, n := range b.Nodes {
+		p := profileOf(n, b.Clock)
+		bf.profiles[n] = &p
+	}
+	return bf
+}
+
+// candidates are the nodes w can start on now, for d, without delaying a
+// reservation.
+func (bf *backfill) candidates(w Workload, d time.Duration) []*SimulatedNode {
+	var out []*SimulatedNode
+	for _, n := range bf.b.Nodes {
+		if n.CanAccept(w) && bf.profiles[n].fits(bf.b.Clock, d, w.CPU, w.Memory) {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// started records w starting on n now.
+func (bf *backfill) started(w Workload, n *SimulatedNode, d time.Duration) {
+	bf.profiles[n].reserve(bf.b.Clock, d, w.CPU, w.Memory)
+}
+
+// block reserves the earliest start of w (first node in order on ties), if
+// the discipline still makes reservations. Under EASY only the first
+// blocked job gets one.
+func (bf *backfill) block(w Workload, d time.Duration, q QueueDiscipline) {
+	if q == QueueEASY && bf.reserved > 0 {
+		return
+	}
+	var best *SimulatedNode
+	var at time.Time
+	for _, n := range bf.b.Nodes {
+		if t, ok := bf.profiles[n].earliest(d, w.CPU, w.Memory); ok && (best == nil || t.Before(at)) {
+			best, at = n, t
+		}
+	}
+	if best != nil {
+		bf.profiles[best].reserve(at, d, w.CPU, w.Memory)
+		bf.reserved++
+	}
+}
+
+// estimate is the runtime the queue discipline plans w with: the runtime
+// predictor's estimate if set, else the true duration.
+func (b *BaseSim) estimate(w Workload) time.Duration {
+	if b.Runtime == nil {
+		return w.Duration
+	}
+	d := time.Duration(b.jobView(w).EstimatedDuration * float64(time.Second))
+	if d <= 0 {
+		d = time.Second
+	}
+	return d
+}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+// On one 5-core node, A (3 cores, 10m) runs when wide (5 cores, 5m), short
+// (1 core, 5m) and long (1 core, 20m) arrive. wide cannot start until A
+// ends; short ends before then, long does not.
+func TestQueueDisciplineReservations(t *testing.T) {
+	s := time.Second
+	m := time.Minute
+	for _, tc := range []struct {
+		q    QueueDiscipline
+		want map[string]time.Duration // start after epoch
+	}{
+		// Everything that fits starts: wide waits for long.
+		{QueueFirstFit, map[string]time.Duration{"A": 0, "wide": 20*m + s, "short": s, "long": s}},
+		// Nothing overtakes wide.
+		{QueueFCFS, map[string]time.Duration{"A": 0, "wide": 10 * m, "short": 15 * m, "long": 15 * m}},
+		// wide is reserved at 10m; short backfills, long would delay wide.
+		{QueueEASY, map[string]time.Duration{"A": 0, "wide": 10 * m, "short": s, "long": 15 * m}},
+		{QueueConservative, map[string]time.Duration{"A": 0, "wide": 10 * m, "short": s, "long": 15 * m}},
+	} {
+		t.Run(tc.q.String(), func(t *testing.T) {
+			b := &BaseSim{}
+			b.Init([]*SimulatedNode{NewNode("n0", 5, 100, 0)}, firstNode{})
+			b.SetScheduleBatchSize(10)
+			b.Queue = tc.q
+			b.AddWorkload(Workload{ID: "A", SubmitTime: epoch, CPU: 3, Memory: 1, Duration: 10 * m})
+			b.AddWorkload(Workload{ID: "wide", SubmitTime: epoch.Add(s), CPU: 5, Memory: 1, Duration: 5 * m})
+			b.AddWorkload(Workload{ID: "short", SubmitTime: epoch.Add(s), CPU: 1, Memory: 1, Duration: 5 * m})
+			b.AddWorkload(Workload{ID: "long", SubmitTime: epoch.Add(s), CPU: 1, Memory: 1, Duration: 20 * m})
+			b.Run()
+
+			got := map[string]time.Duration{}
+			for _, e := range b.Logs() {
+				got[e.JobID] = e.Start.Sub(epoch)
+			}
+			for id, want := range tc.want {
+				if d, ok := got[id]; !ok || d != want {
+					t.Errorf("%s started at %v (placed %v), want %v", id, d, ok, want)
+				}
+			}
+		})
+	}
+}
+
+// Conservative backfilling reserves every blocked job, EASY only the first:
+// a job that fits beside the first reservation but overlaps the second is
+// backfilled by EASY only.
+func TestConservativeProtectsLaterReservations(t *testing.T) {
+	m := time.Minute
+	for _, tc := range []struct {
+		q    QueueDiscipline
+		want map[string]time.Duration
+	}{
+		{QueueEASY, map[string]time.Duration{"first": 10 * m, "late": m, "second": 36 * m}},
+		{QueueConservative, map[string]time.Duration{"first": 10 * m, "late": 40 * m, "second": 30 * m}},
+	} {
+		t.Run(tc.q.String(), func(t *testing.T) {
+			b := &BaseSim{}
+			b.Init([]*SimulatedNode{NewNode("n0", 4, 100, 0)}, firstNode{})
+			b.SetScheduleBatchSize(10)
+			b.Queue = tc.q
+			// A holds 2 cores until 10m, B 1 core until 30m.
+			b.AddWorkload(Workload{ID: "A", SubmitTime: epoch, CPU: 2, Memory: 1, Duration: 10 * m})
+			b.AddWorkload(Workload{ID: "B", SubmitTime: epoch, CPU: 1, Memory: 1, Duration: 30 * m})
+			// first is reserved 2 cores on [10m, 20m), second 4 cores on
+			// [30m, 40m). late fits its core beside first but runs into
+			// second.
+			b.AddWorkload(Workload{ID: "first", SubmitTime: epoch.Add(m), CPU: 2, Memory: 1, Duration: 10 * m})
+			b.AddWorkload(Workload{ID: "second", SubmitTime: epoch.Add(m), CPU: 4, Memory: 1, Duration: 10 * m})
+			b.AddWorkload(Workload{ID: "late", SubmitTime: epoch.Add(m), CPU: 1, Memory: 1, Duration: 35 * m})
+			b.Run()
+
+			got := map[string]time.Duration{}
+			for _, e := range b.Logs() {
+				got[e.JobID] = e.Start.Sub(epoch)
+			}
+			for id, want := range tc.want {
+				if d, ok := got[id]; !ok || d != want {
+					t.Errorf("%s started at %v (placed %v), want %v", id, d, ok, want)
+				}
+			}
+		})
+	}
+}
@@ -3,7 +3,6 @@ package core
 import (
 	"context"
 	"math"
-	"sort"
 	"time"
 )
 
@@ -27,15 +26,57 @@ type BaseSim struct {
 	Select SelectFunc // optional: if set, used first
 	Policy Policy     // generic policy (cisched, carbonscaler, etc.)
 	CICalc func(n *SimulatedNode, w Workload, at time.Time) float64
+	// EnergyCalc, if set, fills LogEntry.EnergyKWh (e.g. metrics.EnergyKWh).
+	EnergyCalc func(n *SimulatedNode, w Workload) float64
+	// Runtime, if set, replaces the duration policies see in
+	// Job.EstimatedDuration; jobs still run for their true duration, which
+	// is reported back to it on completion.
+	Runtime RuntimePredictor
+
+	// Shift enables temporal shifting: if Policy implements Deferrer, jobs
+	// with a deadline may be held back until a lower-carbon start time.
+	Shift bool
+
+	// Queue is the order jobs start in (default QueueFirstFit); the
+	// backfilling disciplines plan with the Runtime estimates.
+	Queue QueueDiscipline
+
+	// Priority, if set, orders the queue before every pass (default:
+	// submission order).
+	Priority *Multifactor
+
+	// Preempt, if set, lets jobs labelled preemptible be suspended and
+	// resumed later (see Preemption).
+	Preempt *Preemption
+
+	kernel Kernel
+	queue  WaitQueue
+	held   map[string]time.Time // job ID -> hold-until
+	since  map[string]time.Time // job ID -> first deferral
+
+	running     map[string]*segment  // preemptible jobs by ID
+	jobs        map[string]*jobState // suspended at least once
+	requeue     []Workload           // suspended during a pass
+	tickPending bool
+	tickAt      time.Time
 }
 
+// Init resets the simulator. The clock starts at the zero time and jumps to
+// the first arrival, so runs depend only on their inputs, never on wall time.
 func (b *BaseSim) Init(nodes []*SimulatedNode, pol Policy) {
-	b.Clock = time.Now()
+	b.Clock = time.Time{}
 	b.Nodes = nodes
 	b.Batch = 1
 	b.Pending = nil
 	b.LogsBuf = nil
 	b.Policy = pol
+	b.kernel = Kernel{}
+	b.held = nil
+	b.since = nil
+	b.running = map[string]*segment{}
+	b.jobs = map[string]*jobState{}
+	b.requeue = nil
+	b.tickPending = false
 }
 
 func (b *BaseSim) SetScheduleBatchSize(n int) {
@@ -46,89 +87,289 @@ func (b *BaseSim) SetScheduleBatchSize(n int) {
 func (b *BaseSim) AddWorkload(j Workload) { b.Pending = append(b.Pending, j) }
 func (b *BaseSim) Logs() []LogEntry       { return b.LogsBuf }
 
-// simple eventless loop: process in submit-time order, greedy at current clock
-func (b *BaseSim) Run() {
-	sort.Slice(b.Pending, func(i, j int) bool { return b.Pending[i].SubmitTime.Before(b.Pending[j].SubmitTime) })
-	queue := make([]Workload, 0, len(b.Pending))
-	i := 0
-	for i < len(b.Pending) || len(queue) > 0 {
-		// advance time to next submit if idle
-		if len(queue) == 0 && i < len(b.Pending) && b.Clock.Before(b.Pending[i].SubmitTime) {
-			b.Clock = b.Pending[i].SubmitTime
-		}
-		// release resources at current time
-		for _, n := range b.Nodes {
-			n.Release(b.Clock)
-		}
-		// enqueue arrivals at/before now
-		for i < len(b.Pending) && !b.Pending[i].SubmitTime.After(b.Clock) {
-			queue = append(queue, b.Pending[i])
-			i++
-		}
-		if len(queue) == 0 {
-			continue
-		}
+// Now implements Clock with the simulated time.
+func (b *BaseSim) Now() time.Time { return b.Clock }
+
+// Run drives the simulation on the event kernel: arrivals and reservation
+// completions are popped in (time, kind, insertion) order, and after every
+// distinct timestamp the queue gets one scheduling pass of up to Batch jobs.
+func (b *BaseSim) Run() { b.RunContext(context.Background()) }
 
-		// schedule up to Batch
-		next := queue[:0]
-		scheduled := 0
-		for _, w := range queue {
-			if scheduled >= b.Batch {
-				next = append(next, w)
-				continue
+// RunContext is Run that stops early, returning ctx.Err(), once ctx is done.
+func (b *BaseSim) RunContext(ctx context.Context) error {
+	b.kernel.Clock = b.Clock
+	for _, w := range b.Pending {
+		b.kernel.Schedule(Event{Time: w.SubmitTime, Kind: EventArrival, Workload: w})
+	}
+	b.queue = WaitQueue{}
+	if b.Priority != nil {
+		b.Priority.reset()
+	}
+	for step := 0; ; step++ {
+		if step%1024 == 0 && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		batch := b.kernel.NextBatch()
+		if len(batch) == 0 {
+			break
+		}
+		b.Clock = b.kernel.Clock
+		for _, e := range batch {
+			switch e.Kind {
+			case EventCompletion:
+				if b.Preempt != nil && e.Workload.Preemptible() {
+					r, ok := b.running[e.Workload.ID]
+					if !ok || r.node != e.Node || !r.end.Equal(e.Time) {
+						continue // superseded by a suspension
+					}
+					delete(b.running, e.Workload.ID)
+				}
+				e.Node.Release(b.Clock)
+				if b.Priority != nil {
+					b.Priority.stopped(e.Workload.ID, b.Clock)
+				}
+				if b.Runtime != nil {
+					b.Runtime.ObserveRuntime(JobView(e.Workload), e.Workload.Duration)
+				}
+			case EventArrival:
+				b.queue.Push(e.Workload)
 			}
-			n := b.selectNode(w)
-			if n == nil {
-				next = append(next, w)
-				continue
+		}
+		if b.Preempt != nil {
+			b.ciTick()
+			b.flushRequeue()
+		}
+		if b.queue.Len() > 0 {
+			if b.Priority != nil {
+				b.Priority.order(&b.queue, b.Clock)
 			}
+			b.schedulePass()
+		}
+	}
+	return nil
+}
+
+// At injects a bare event (CI change, timer) that triggers a scheduling pass at t.
+func (b *BaseSim) At(t time.Time, kind EventKind) { b.kernel.At(t, kind) }
 
-			start := b.Clock
+// Unplaced returns the workloads still queued when Run returned (requests
+// no node can ever satisfy).
+func (b *BaseSim) Unplaced() []Workload { return b.queue.Items() }
 
-			var ci float64
-			if b.CICalc != nil {
-				ci = b.CICalc(n,w,start)
+// schedulePass places up to Batch queued jobs at the current clock, in queue
+// order. Jobs larger than the free capacity of every node are skipped without
+// consulting the policy, and the pass ends once nothing queued can fit -
+// unless priority preemption could still make room. Jobs that cannot start
+// are handled by the Queue discipline.
+func (b *BaseSim) schedulePass() {
+	maxCPU, maxMem := b.maxAvailable()
+	minCPU, minMem := b.queue.MinRequest()
+	evict := b.Preempt != nil && b.Preempt.Priority && len(b.running) > 0
+	var bf *backfill // built at the first blocked job (EASY, Conservative)
+	blocked := func(w Workload) bool {
+		switch b.Queue {
+		case QueueFCFS:
+			return false
+		case QueueEASY, QueueConservative:
+			if bf == nil {
+				bf = b.newBackfill()
 			}
+			bf.block(w, b.estimate(w), b.Queue)
+		}
+		return true
+	}
+	scheduled := 0
+	b.queue.Each(func(i int, qw *Workload) bool {
+		if scheduled >= b.Batch || (!evict && (maxCPU < minCPU || maxMem < minMem)) {
+			return false
+		}
+		w := *qw
+		fits := w.CPU <= maxCPU && w.Memory <= maxMem
+		if !fits && !evict {
+			return blocked(w)
+		}
+		if b.holdBack(w) {
+			return true
+		}
+		var n *SimulatedNode
+		if fits {
+			nodes := b.Nodes
+			if bf != nil {
+				nodes = bf.candidates(w, b.estimate(w))
+			}
+			n = b.selectNode(w, nodes)
+		}
+		if n == nil && evict {
+			if n = b.preemptFor(w); n != nil {
+				bf = nil // evictions invalidate the profiles
+			}
+		}
+		if n == nil {
+			return blocked(w)
+		}
+		b.place(w, n)
+		if bf != nil {
+			bf.started(w, n, b.estimate(w))
+		}
+
+		b.queue.Remove(i)
+		scheduled++
+		maxCPU, maxMem = b.maxAvailable()
+		evict = evict && len(b.running) > 0
+		return true
+	})
+	if b.Preempt != nil {
+		b.flushRequeue()
+	}
+}
 
+// place starts w on n at the current clock and logs it.
+func (b *BaseSim) place(w Workload, n *SimulatedNode) {
+	start := b.Clock
 
-			n.Reserve(w, start)
-			end := start.Add(w.Duration)
+	var ci float64
+	if b.CICalc != nil {
+		ci = b.CICalc(n, w, start)
+	}
+
+	n.Reserve(w, start)
+	end := start.Add(w.Duration)
+	orig, st := w, b.jobs[w.ID]
+	if st != nil {
+		orig = st.orig
+	}
+	b.kernel.Schedule(Event{Time: end, Kind: EventCompletion, Workload: orig, Node: n})
 
-			b.LogsBuf = append(b.LogsBuf, LogEntry{
-				JobID:  w.ID,
-				Node:   n.Name,
-				Submit: w.SubmitTime,
-				Start:  start,
-				End:    end,
-				WaitMS: int64(start.Sub(w.SubmitTime) / time.Millisecond),
-				CICost: ci,
-			})
+	entry := LogEntry{
+		JobID:  w.ID,
+		Node:   n.Name,
+		Submit: w.SubmitTime,
+		Start:  start,
+		End:    end,
+		WaitMS: int64(start.Sub(w.SubmitTime) / time.Millisecond),
+		CICost: ci,
+		CPU:    w.CPU,
+		Mem:    w.Memory,
+		Tag:    w.Tag,
+		Tenant: w.Tenant(),
+		SiteID: n.SiteID,
+	}
+	if st != nil {
+		entry.WaitMS = int64(start.Sub(st.suspended) / time.Millisecond)
+		entry.Segment = st.preemptions
+		entry.Preemptions = st.preemptions
+		entry.Restore = b.Preempt.Overhead
+	}
+	if b.EnergyCalc != nil {
+		entry.EnergyKWh = b.EnergyCalc(n, w)
+	}
+	if b.Runtime != nil {
+		entry.PredRuntime = time.Duration(b.jobView(w).EstimatedDuration * float64(time.Second))
+		n.Reservations[len(n.Reservations)-1].Estimate = start.Add(entry.PredRuntime)
+	}
+	if w.Deadline > 0 {
+		entry.Deadline = w.SubmitTime.Add(w.Deadline)
+	}
+	if t, ok := b.since[w.ID]; ok {
+		entry.DeferMS = int64(start.Sub(t) / time.Millisecond)
+		delete(b.since, w.ID)
+		delete(b.held, w.ID)
+	}
+	b.LogsBuf = append(b.LogsBuf, entry)
+	if b.Priority != nil {
+		b.Priority.started(w, start)
+	}
 
-			scheduled++
+	if b.Preempt != nil && w.Preemptible() {
+		r := &segment{w: w, orig: orig, node: n, start: start, end: end, entry: len(b.LogsBuf) - 1}
+		if st != nil {
+			r.restart = b.Preempt.Overhead
 		}
-		queue = next
+		b.running[w.ID] = r
+		b.ensureTick()
+	}
+}
 
-		// advance time to earliest reservation end
-		earliest := time.Time{}
-		for _, n := range b.Nodes {
-			if t := n.NextReleaseAfter(b.Clock); !t.IsZero() {
-				if earliest.IsZero() || t.Before(earliest) {
-					earliest = t
-				}
-			}
+// holdBack reports whether w is (still) being held back by the policy. A new
+// hold schedules a timer so the job is re-evaluated when it expires.
+func (b *BaseSim) holdBack(w Workload) bool {
+	if until, ok := b.held[w.ID]; ok && until.After(b.Clock) {
+		return true
+	}
+	d, ok := b.Policy.(Deferrer)
+	if !b.Shift || !ok || w.Deadline <= 0 {
+		return false
+	}
+	j := b.jobView(w)
+	until, ok := d.Defer(WithClock(context.Background(), b), j, b.nodeView())
+	if !ok {
+		return false
+	}
+	if latest := j.LatestStart(); until.After(latest) {
+		until = latest
+	}
+	if !until.After(b.Clock) {
+		return false
+	}
+	if b.held == nil {
+		b.held = map[string]time.Time{}
+		b.since = map[string]time.Time{}
+	}
+	if _, ok := b.since[w.ID]; !ok {
+		b.since[w.ID] = b.Clock
+	}
+	b.held[w.ID] = until
+	b.kernel.At(until, EventTimer)
+	return true
+}
+
+// jobView is JobView with the runtime estimate of b.Runtime, if set.
+func (b *BaseSim) jobView(w Workload) Job {
+	j := JobView(w)
+	if b.Runtime != nil {
+		st := b.jobs[w.ID]
+		if st == nil {
+			j.EstimatedDuration = b.Runtime.PredictRuntime(j).Seconds()
+			return j
 		}
-		if earliest.IsZero() {
-			earliest = b.Clock.Add(1 * time.Second)
+		// Resumed: the prediction for the whole job less the work kept.
+		d := b.Runtime.PredictRuntime(JobView(st.orig)) - st.done
+		if d < 0 {
+			d = 0
 		}
-		b.Clock = earliest
+		j.EstimatedDuration = (d + b.Preempt.Overhead).Seconds()
 	}
+	return j
 }
 
-// selection order: custom SelectFunc → policy.Score → least-loaded fallback
-func (b *BaseSim) selectNode(w Workload) *SimulatedNode {
+// nodeView copies the nodes by value for Policy.Score / Deferrer.Defer.
+func (b *BaseSim) nodeView() []SimulatedNode {
+	view := make([]SimulatedNode, 0, len(b.Nodes))
+	for _, np := range b.Nodes {
+		view = append(view, *np)
+	}
+	return view
+}
+
+// maxAvailable returns the largest free CPU and memory over all nodes
+// (not necessarily on the same node): a necessary condition for any fit.
+func (b *BaseSim) maxAvailable() (cpu, mem float64) {
+	for _, n := range b.Nodes {
+		cpu = math.Max(cpu, n.AvailableCPU)
+		mem = math.Max(mem, n.AvailableMemory)
+	}
+	return cpu, mem
+}
+
+// selection order: custom SelectFunc → policy.Score → least-loaded fallback,
+// among nodes (all of b.Nodes, or those a backfill reservation leaves open)
+func (b *BaseSim) selectNode(w Workload, nodes []*SimulatedNode) *SimulatedNode {
+	if len(nodes) == 0 {
+		return nil
+	}
 	// 1) explicit override
 	if b.Select != nil {
-		if n := b.Select(w, b.Nodes); n != nil {
+		if n := b.Select(w, nodes); n != nil {
 			return n
 		}
 	}
@@ -136,26 +377,18 @@ func (b *BaseSim) selectNode(w Workload) *SimulatedNode {
 	// 2) policy-driven selection via Score
 	if b.Policy != nil {
 		// Build []SimulatedNode view (by value) from []*SimulatedNode
-		view := make([]SimulatedNode, 0, len(b.Nodes))
-		for _, np := range b.Nodes {
+		view := make([]SimulatedNode, 0, len(nodes))
+		for _, np := range nodes {
 			view = append(view, *np)
 		}
 
 		// Workload → Job wrapper for Score; keep CanAccept using Workload
-		j := Job{
-			ID:                w.ID,
-			CPUReq:            w.CPU,
-			MemReq:            w.Memory,
-			EstimatedDuration: w.Duration.Seconds(),
-			SubmitAt:          w.SubmitTime,
-			Labels:            w.Labels,
-			Tags:              nil, // fill if you route tags
-			DeadlineMs:        0,   // fill if relevant
-		}
-
-		if scores, err := b.Policy.Score(context.Background(), j, view); err == nil && len(scores) > 0 {
+		j := b.jobView(w)
+
+		ctx := WithClock(context.Background(), b)
+		if scores, err := b.Policy.Score(ctx, j, view); err == nil && len(scores) > 0 {
 			if id, ok := ArgMin(scores); ok {
-				for _, n := range b.Nodes {
+				for _, n := range nodes {
 					if n.Name == id && n.CanAccept(w) {
 						return n
 					}
@@ -167,7 +400,7 @@ func (b *BaseSim) selectNode(w Workload) *SimulatedNode {
 	// 3) least-loaded fallback
 	var best *SimulatedNode
 	bestScore := math.MaxFloat64
-	for _, n := range b.Nodes {
+	for _, n := range nodes {
 		if !n.CanAccept(w) {
 			continue
 		}
@@ -0,0 +1,137 @@
+package core
+
+import (
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// RandomWalk is a seeded stochastic CI process evaluated at simulated time:
+// either a random walk reflected into [Min, Max] or, when Theta > 0, an
+// Ornstein–Uhlenbeck process reverting to Mean. The path is generated step by
+// step from Origin, so a given (Seed, Origin) always yields the same values
+// regardless of the order in which times are queried. Values between steps
+// are interpolated linearly.
+type RandomWalk struct {
+	Min, Max float64
+	Step     time.Duration
+	Seed     int64
+	Origin   time.Time // zero: each UTC day starts its own path at midnight
+
+	Mean  float64 // OU long-run mean (defaults to the midpoint of Min/Max)
+	Theta float64 // OU reversion rate per step; 0 selects the bounded walk
+	Sigma float64 // per-step noise; defaults to (Max-Min)/10
+
+	mu    sync.Mutex
+	paths map[int64][]float64 // keyed by origin (unix seconds)
+	rngs  map[int64]*rand.Rand
+}
+
+// NewRandomWalk returns a bounded random walk between min and max.
+func NewRandomWalk(min, max float64, step time.Duration, seed int64) *RandomWalk {
+	return &RandomWalk{Min: min, Max: max, Step: step, Seed: seed}
+}
+
+// NewOU returns an Ornstein–Uhlenbeck process; min/max clamp it when max > min.
+func NewOU(mean, theta, sigma float64, step time.Duration, seed int64) *RandomWalk {
+	return &RandomWalk{Mean: mean, Theta: theta, Sigma: sigma, Step: step, Seed: seed,
+		Min: math.Inf(-1), Max: math.Inf(1)}
+}
+
+// SeedFor derives a per-name seed so nodes sharing a run seed still get
+// independent paths.
+func SeedFor(seed int64, name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return seed ^ int64(h.Sum64())
+}
+
+// At returns the process value at t.
+func (r *RandomWalk) At(t time.Time) float64 {
+	origin := r.Origin
+	if origin.IsZero() {
+		origin = t.UTC().Truncate(24 * time.Hour)
+	}
+	step := r.Step
+	if step <= 0 {
+		step = 5 * time.Minute
+	}
+	if !t.After(origin) {
+		return r.value(origin, 0)
+	}
+	off := t.Sub(origin)
+	k := int(off / step)
+	frac := float64(off%step) / float64(step)
+	v0 := r.value(origin, k)
+	if frac == 0 {
+		return v0
+	}
+	return v0 + frac*(r.value(origin, k+1)-v0)
+}
+
+func (r *RandomWalk) value(origin time.Time, k int) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := origin.Unix()
+	if r.paths == nil {
+		r.paths = map[int64][]float64{}
+		r.rngs = map[int64]*rand.Rand{}
+	}
+	path, ok := r.paths[key]
+	if !ok {
+		r.rngs[key] = rand.New(rand.NewSource(r.Seed ^ key))
+		path = []float64{r.mean()}
+	}
+	rng := r.rngs[key]
+	for len(path) <= k {
+		path = append(path, r.next(path[len(path)-1], rng))
+	}
+	r.paths[key] = path
+	return path[k]
+}
+
+func (r *RandomWalk) mean() float64 {
+	if r.Mean != 0 || math.IsInf(r.Min, 0) || math.IsInf(r.Max, 0) {
+		return r.Mean
+	}
+	return (r.Min + r.Max) / 2
+}
+
+func (r *RandomWalk) sigma() float64 {
+	if r.Sigma > 0 {
+		return r.Sigma
+	}
+	if r.Max > r.Min && !math.IsInf(r.Max-r.Min, 0) {
+		return (r.Max - r.Min) / 10
+	}
+	return 1
+}
+
+func (r *RandomWalk) next(x float64, rng *rand.Rand) float64 {
+	z := rng.NormFloat64()
+	if r.Theta > 0 {
+		// exact OU transition over one step
+		decay := math.Exp(-r.Theta)
+		sd := r.sigma() * math.Sqrt((1-decay*decay)/(2*r.Theta))
+		return math.Max(r.Min, math.Min(r.Max, r.mean()+(x-r.mean())*decay+sd*z))
+	}
+	return reflectInto(x+r.sigma()*z, r.Min, r.Max)
+}
+
+// reflectInto folds x back into [lo, hi] like a ball bouncing off the bounds.
+func reflectInto(x, lo, hi float64) float64 {
+	if hi <= lo {
+		return lo
+	}
+	w := hi - lo
+	y := math.Mod(x-lo, 2*w)
+	if y < 0 {
+		y += 2 * w
+	}
+	if y > w {
+		y = 2*w - y
+	}
+	return lo + y
+}
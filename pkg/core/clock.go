@@ -0,0 +1,42 @@
+package core
+
+import (
+	"context"
+	"time"
+)
+
+// Clock supplies the current time to scheduling code. The simulator passes
+// its simulated clock; the live CentralUnit uses WallClock.
+type Clock interface {
+	Now() time.Time
+}
+
+// WallClock reads the system time.
+type WallClock struct{}
+
+func (WallClock) Now() time.Time { return time.Now() }
+
+// FixedClock always reports the same instant (replays, what-if scoring).
+type FixedClock time.Time
+
+func (c FixedClock) Now() time.Time { return time.Time(c) }
+
+type clockKey struct{}
+
+// WithClock returns a context carrying c for Policy.Score and friends.
+func WithClock(ctx context.Context, c Clock) context.Context {
+	return context.WithValue(ctx, clockKey{}, c)
+}
+
+// ClockFrom returns the clock carried by ctx, or WallClock if none.
+func ClockFrom(ctx context.Context) Clock {
+	if ctx != nil {
+		if c, ok := ctx.Value(clockKey{}).(Clock); ok && c != nil {
+			return c
+		}
+	}
+	return WallClock{}
+}
+
+// Now is shorthand for ClockFrom(ctx).Now(); policies use it instead of time.Now.
+func Now(ctx context.Context) time.Time { return ClockFrom(ctx).Now() }
@@ -0,0 +1,194 @@
+package core
+
+import (
+	"math"
+	"time"
+)
+
+// CIForecaster returns the expected mean CI (gCO₂/kWh) for a site (or node)
+// over the next durationSec seconds; NaN if the key is unknown.
+type CIForecaster interface {
+	Forecast(key string, now time.Time, durationSec float64) float64
+}
+
+// EnergyEstimator predicts the energy (J) job j draws on node n.
+type EnergyEstimator interface {
+	EstimateJoules(j Job, n Node) float64
+}
+
+// QueueEstimator exposes per-site queueing state.
+type QueueEstimator interface {
+	EstimatedStartDelay(siteID string, j Job, now time.Time) time.Duration
+	Length(siteID string) int
+	HasCapacity(siteID string, j Job, now time.Time) bool
+}
+
+// PriceEstimator returns the electricity price (€/kWh) expected at a site
+// over j's execution window starting at now.
+type PriceEstimator interface {
+	Estimate(siteID string, j Job, now time.Time) float64
+}
+
+// ReproEstimator penalises placements that hurt reproducibility.
+type ReproEstimator interface {
+	Penalty(j Job, n Node) float64
+}
+
+// RuntimePredictor estimates job runtimes for policies in place of the true
+// duration. PredictRuntime receives the JobView of the workload, whose
+// EstimatedDuration is still the true duration: only oracle-style
+// predictors may read it. ObserveRuntime reports each completed job's
+// actual runtime, so predictors can learn online.
+type RuntimePredictor interface {
+	PredictRuntime(j Job) time.Duration
+	ObserveRuntime(j Job, actual time.Duration)
+}
+
+// LinearEnergy is the node power model used by metrics.ComputeCICost: the
+// node's draw at the job's CPU share (n.Power), over the estimated duration.
+// Nodes without a PowerModel are taken as linear from IdleFrac × peak to
+// peak, with peak from n.Metrics["peak_power_w"].
+type LinearEnergy struct {
+	IdleFrac     float64 // default 0.15
+	DefaultPeakW float64 // default 400
+}
+
+func (e LinearEnergy) EstimateJoules(j Job, n Node) float64 {
+	cpuFrac := 0.0
+	if n.CPUCap > 0 {
+		cpuFrac = j.CPUReq / n.CPUCap
+	}
+	pm := n.Power
+	if pm == nil {
+		idle, peak := e.IdleFrac, n.Metrics["peak_power_w"]
+		if idle <= 0 {
+			idle = DefaultIdleFrac
+		}
+		if peak <= 0 {
+			peak = e.DefaultPeakW
+		}
+		if peak <= 0 {
+			peak = DefaultPeakPowerW
+		}
+		pm = LinearPower{IdleW: peak * idle, PeakW: peak}
+	}
+	return pm.Power(cpuFrac) * math.Max(j.EstimatedDuration, 0)
+}
+
+// SimQueue derives queue state from simulated nodes grouped by SiteID.
+type SimQueue struct {
+	Nodes []*SimulatedNode
+}
+
+func (q *SimQueue) HasCapacity(siteID string, j Job, _ time.Time) bool {
+	w := Workload{CPU: j.CPUReq, Memory: j.MemReq}
+	for _, n := range q.Nodes {
+		if n.SiteID == siteID && n.CanAccept(w) {
+			return true
+		}
+	}
+	return false
+}
+
+// EstimatedStartDelay is zero if a site node fits j now, else the time until
+// the earliest reservation ends on a site node large enough for j.
+func (q *SimQueue) EstimatedStartDelay(siteID string, j Job, now time.Time) time.Duration {
+	w := Workload{CPU: j.CPUReq, Memory: j.MemReq}
+	var earliest time.Time
+	for _, n := range q.Nodes {
+		if n.SiteID != siteID || n.TotalCPU < j.CPUReq || n.TotalMemory < j.MemReq {
+			continue
+		}
+		if n.CanAccept(w) {
+			return 0
+		}
+		if t := n.NextReleaseAfter(now); !t.IsZero() && (earliest.IsZero() || t.Before(earliest)) {
+			earliest = t
+		}
+	}
+	if earliest.IsZero() {
+		return 0
+	}
+	return earliest.Sub(now)
+}
+
+// Length counts running reservations at the site.
+func (q *SimQueue) Length(siteID string) int {
+	c := 0
+	for _, n := range q.Nodes {
+		if n.SiteID == siteID {
+			c += len(n.Reservations)
+		}
+	}
+	return c
+}
+
+// FlatPrice is a constant price (€/kWh) per site.
+type FlatPrice map[string]float64
+
+func (p FlatPrice) Estimate(siteID string, _ Job, _ time.Time) float64 { return p[siteID] }
+
+// SitePrice prices a job at the mean of its site's tariff over the job's
+// estimated window, sampled every 5 minutes (sites without one are free).
+type SitePrice map[string]Series
+
+func (p SitePrice) Estimate(siteID string, j Job, now time.Time) float64 {
+	s, ok := p[siteID]
+	if !ok || s == nil {
+		return 0
+	}
+	return SeriesMean(s, now, time.Duration(j.EstimatedDuration*float64(time.Second)), 5*time.Minute)
+}
+
+// SeriesMean averages s over [start, start+d) sampled every step (at most
+// 1000 samples); d ≤ 0 returns s at start.
+func SeriesMean(s Series, start time.Time, d, step time.Duration) float64 {
+	if d <= 0 || step <= 0 {
+		return s.At(start)
+	}
+	if d/step > 1000 {
+		step = d / 1000
+	}
+	sum, n := 0.0, 0
+	for t := start; t.Before(start.Add(d)); t = t.Add(step) {
+		sum += s.At(t)
+		n++
+	}
+	return sum / float64(n)
+}
+
+// LabelRepro penalises nodes whose labels differ from the job's labels on
+// any of Keys (e.g. "arch" or "node_type" pinned by a reproducible run).
+// Keys the job does not set are ignored. The keys "site_id" and "node"
+// match the node's SiteID and ID when it has no such label.
+type LabelRepro struct {
+	Keys   []string
+	Weight float64 // per mismatching key; default 1
+}
+
+func (r LabelRepro) Penalty(j Job, n Node) float64 {
+	w := r.Weight
+	if w <= 0 {
+		w = 1
+	}
+	p := 0.0
+	for _, k := range r.Keys {
+		if want, ok := j.Labels[k]; ok && nodeLabel(n, k) != want {
+			p += w
+		}
+	}
+	return p
+}
+
+func nodeLabel(n Node, k string) string {
+	if v, ok := n.Labels[k]; ok {
+		return v
+	}
+	switch k {
+	case "site_id":
+		return n.SiteID
+	case "node":
+		return n.ID
+	}
+	return ""
+}
@@ -0,0 +1,137 @@
+package core
+
+import (
+	"container/heap"
+	"time"
+)
+
+// EventKind identifies what happened at an event's timestamp.
+// The numeric order is also the processing order for events that share a
+// timestamp: resources are released before new arrivals are considered.
+type EventKind int
+
+const (
+	EventCompletion EventKind = iota // a reservation ends and its node frees resources
+	EventArrival                     // a workload is submitted
+	EventCIChange                    // carbon intensity changed; re-evaluate the queue
+	EventTimer                       // generic wake-up (e.g. deferred jobs)
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventCompletion:
+		return "completion"
+	case EventArrival:
+		return "arrival"
+	case EventCIChange:
+		return "ci_change"
+	case EventTimer:
+		return "timer"
+	}
+	return "unknown"
+}
+
+// Event is one entry of the simulation timeline.
+type Event struct {
+	Time     time.Time
+	Kind     EventKind
+	Workload Workload
+	Node     *SimulatedNode
+
+	seq uint64 // insertion order, breaks ties deterministically
+}
+
+// eventHeap orders by (Time, Kind, seq).
+type eventHeap []Event
+
+func (h eventHeap) Len() int { return len(h) }
+func (h eventHeap) Less(i, j int) bool {
+	if !h[i].Time.Equal(h[j].Time) {
+		return h[i].Time.Before(h[j].Time)
+	}
+	if h[i].Kind != h[j].Kind {
+		return h[i].Kind < h[j].Kind
+	}
+	return h[i].seq < h[j].seq
+}
+func (h eventHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *eventHeap) Push(x any)   { *h = append(*h, x.(Event)) }
+func (h *eventHeap) Pop() any {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	*h = old[:n-1]
+	return e
+}
+
+// EventQueue is a priority queue of events with stable tie-breaking:
+// equal (Time, Kind) pairs pop in the order they were pushed.
+type EventQueue struct {
+	h   eventHeap
+	seq uint64
+}
+
+func (q *EventQueue) Len() int { return len(q.h) }
+
+func (q *EventQueue) Push(e Event) {
+	e.seq = q.seq
+	q.seq++
+	heap.Push(&q.h, e)
+}
+
+// Pop removes and returns the earliest event. It panics on an empty queue.
+func (q *EventQueue) Pop() Event { return heap.Pop(&q.h).(Event) }
+
+// Peek returns the earliest event without removing it.
+func (q *EventQueue) Peek() (Event, bool) {
+	if len(q.h) == 0 {
+		return Event{}, false
+	}
+	return q.h[0], true
+}
+
+// Kernel couples an event queue with a monotonic simulation clock.
+type Kernel struct {
+	Clock  time.Time
+	Events EventQueue
+}
+
+// Now implements Clock.
+func (k *Kernel) Now() time.Time { return k.Clock }
+
+// Schedule adds an event to the timeline.
+func (k *Kernel) Schedule(e Event) { k.Events.Push(e) }
+
+// At schedules a bare event of the given kind (CI change, timer) at t.
+func (k *Kernel) At(t time.Time, kind EventKind) { k.Events.Push(Event{Time: t, Kind: kind}) }
+
+// Next pops the earliest event and advances the clock to it. Events stamped
+// before the current clock are delivered at the current clock (time never
+// runs backwards).
+func (k *Kernel) Next() (Event, bool) {
+	if k.Events.Len() == 0 {
+		return Event{}, false
+	}
+	e := k.Events.Pop()
+	if e.Time.After(k.Clock) {
+		k.Clock = e.Time
+	}
+	return e, true
+}
+
+// NextBatch pops every event due at the next timestamp (after clamping to the
+// clock) and returns them in processing order.
+func (k *Kernel) NextBatch() []Event {
+	first, ok := k.Next()
+	if !ok {
+		return nil
+	}
+	batch := []Event{first}
+	for {
+		e, ok := k.Events.Peek()
+		if !ok || e.Time.After(k.Clock) {
+			return batch
+		}
+		batch = append(batch, k.Events.Pop())
+	}
+}
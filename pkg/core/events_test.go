@@ -0,0 +1,92 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEventQueueOrder(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ev := func(id string, at time.Duration, k EventKind) Event {
+		return Event{Time: t0.Add(at), Kind: k, Workload: Workload{ID: id}}
+	}
+	for _, tc := range []struct {
+		name string
+		push []Event
+		want []string
+	}{
+		{"by time", []Event{
+			ev("c", 3*time.Second, EventArrival),
+			ev("a", time.Second, EventArrival),
+			ev("b", 2*time.Second, EventArrival),
+		}, []string{"a", "b", "c"}},
+		{"completions before arrivals at a time", []Event{
+			ev("arrive", 0, EventArrival),
+			ev("timer", 0, EventTimer),
+			ev("ci", 0, EventCIChange),
+			ev("done", 0, EventCompletion),
+		}, []string{"done", "arrive", "ci", "timer"}},
+		{"insertion order on ties", []Event{
+			ev("a1", time.Second, EventArrival),
+			ev("c1", time.Second, EventCompletion),
+			ev("a2", time.Second, EventArrival),
+			ev("c2", time.Second, EventCompletion),
+			ev("a3", time.Second, EventArrival),
+		}, []string{"c1", "c2", "a1", "a2", "a3"}},
+		{"time before kind", []Event{
+			ev("late-done", 2*time.Second, EventCompletion),
+			ev("early-timer", time.Second, EventTimer),
+		}, []string{"early-timer", "late-done"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var q EventQueue
+			for _, e := range tc.push {
+				q.Push(e)
+			}
+			var got []string
+			for q.Len() > 0 {
+				got = append(got, q.Pop().Workload.ID)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("popped %v, want %v", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Fatalf("popped %v, want %v", got, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestKernelNextBatch(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var k Kernel
+	k.Clock = t0.Add(time.Minute)
+	k.Schedule(Event{Time: t0, Kind: EventArrival, Workload: Workload{ID: "past"}})
+	k.Schedule(Event{Time: t0.Add(time.Minute), Kind: EventCompletion, Workload: Workload{ID: "now"}})
+	k.Schedule(Event{Time: t0.Add(2 * time.Minute), Kind: EventArrival, Workload: Workload{ID: "later"}})
+
+	// Past events are delivered at the clock, in time order with those due now.
+	b := k.NextBatch()
+	if len(b) != 2 || b[0].Workload.ID != "past" || b[1].Workload.ID != "now" {
+		t.Fatalf("first batch %v", ids(b))
+	}
+	if !k.Clock.Equal(t0.Add(time.Minute)) {
+		t.Fatalf("clock %v ran backwards or ahead", k.Clock)
+	}
+	if b = k.NextBatch(); len(b) != 1 || b[0].Workload.ID != "later" || !k.Clock.Equal(t0.Add(2*time.Minute)) {
+		t.Fatalf("second batch %v at %v", ids(b), k.Clock)
+	}
+	if b = k.NextBatch(); b != nil {
+		t.Fatalf("batch %v from an empty kernel", ids(b))
+	}
+}
+
+func ids(es []Event) []string {
+	out := make([]string, len(es))
+	for i, e := range es {
+		out[i] = e.Workload.ID
+	}
+	return out
+}
@@ -0,0 +1,182 @@
+package core
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Defaults of Multifactor, as in Slurm.
+const (
+	DefaultPriorityMaxAge = 7 * 24 * time.Hour
+	DefaultUsageHalfLife  = 7 * 24 * time.Hour
+	DefaultTenantShare    = 1.0
+)
+
+// Multifactor orders BaseSim's queue by a weighted job priority, like
+// Slurm's multifactor plugin: each factor is normalised to [0,1] and
+//
+//	priority = Age·age + FairShare·fairshare + Class·class
+//
+// age grows linearly with the time queued up to MaxAge; class is the job's
+// Workload.Priority over the highest one queued; fairshare is 2^(-U/S) for
+// the job's tenant (Workload.Tenant), with U its share of the decayed CPU
+// usage of all tenants and S its normalised share. Usage accrues in
+// core-seconds while jobs run and halves every HalfLife.
+type Multifactor struct {
+	Age, FairShare, Class float64
+
+	MaxAge   time.Duration      // default DefaultPriorityMaxAge
+	HalfLife time.Duration      // default DefaultUsageHalfLife
+	Shares   map[string]float64 // tenant -> share; others get DefaultTenantShare
+
+	tenants []string           // that have run or queued, sorted (fixes the summation order)
+	usage   map[string]float64 // tenant -> decayed core-seconds
+	rate    map[string]float64 // tenant -> cores in use
+	running map[string]tenantRun
+	at      time.Time // usage is up to date until at
+}
+
+type tenantRun struct {
+	tenant string
+	cpu    float64
+}
+
+func (m *Multifactor) reset() {
+	m.tenants = nil
+	m.usage = map[string]float64{}
+	m.rate = map[string]float64{}
+	m.running = map[string]tenantRun{}
+	m.at = time.Time{}
+}
+
+func (m *Multifactor) halfLife() time.Duration {
+	if m.HalfLife > 0 {
+		return m.HalfLife
+	}
+	return DefaultUsageHalfLife
+}
+
+func (m *Multifactor) share(tenant string) float64 {
+	if s, ok := m.Shares[tenant]; ok && s > 0 {
+		return s
+	}
+	return DefaultTenantShare
+}
+
+// advance decays the usage to t and adds what the running jobs used since.
+func (m *Multifactor) advance(t time.Time) {
+	if m.at.IsZero() {
+		m.at = t
+	}
+	dt := t.Sub(m.at)
+	if dt <= 0 {
+		return
+	}
+	h := m.halfLife().Seconds()
+	f := math.Exp2(-dt.Seconds() / h)
+	for _, tenant := range m.tenants {
+		// decayed usage plus ∫ r·2^(-(dt-s)/h) ds over the step
+		m.usage[tenant] = m.usage[tenant]*f + m.rate[tenant]*h/math.Ln2*(1-f)
+	}
+	m.at = t
+}
+
+// started charges w's tenant for its cores from t on.
+func (m *Multifactor) started(w Workload, t time.Time) {
+	m.advance(t)
+	tenant := w.Tenant()
+	m.running[w.ID] = tenantRun{tenant, w.CPU}
+	m.rate[tenant] += w.CPU
+	m.join(tenant)
+}
+
+// join makes tenant's share count towards the normalised shares, with no
+// usage yet.
+func (m *Multifactor) join(tenant string) {
+	if _, ok := m.usage[tenant]; ok {
+		return
+	}
+	m.usage[tenant] = 0
+	i := sort.SearchStrings(m.tenants, tenant)
+	m.tenants = append(m.tenants, "")
+	copy(m.tenants[i+1:], m.tenants[i:])
+	m.tenants[i] = tenant
+}
+
+// stopped ends the charge of job id at t.
+func (m *Multifactor) stopped(id string, t time.Time) {
+	r, ok := m.running[id]
+	if !ok {
+		return
+	}
+	m.advance(t)
+	delete(m.running, id)
+	if m.rate[r.tenant] -= r.cpu; m.rate[r.tenant] < 1e-9 {
+		delete(m.rate, r.tenant)
+	}
+}
+
+// fairShare is 2^(-U/S) for tenant at t (1 for a tenant that has used
+// nothing, 0.5 for one using exactly its share).
+func (m *Multifactor) fairShare(tenant string, t time.Time) float64 {
+	m.advance(t)
+	total, shares := 0.0, 0.0
+	for _, tn := range m.tenants {
+		total += m.usage[tn]
+		shares += m.share(tn)
+	}
+	if total <= 0 {
+		return 1
+	}
+	if _, ok := m.usage[tenant]; !ok {
+		shares += m.share(tenant)
+	}
+	return math.Exp2(-(m.usage[tenant] / total) / (m.share(tenant) / shares))
+}
+
+// order sorts q by descending priority at t, earlier submissions first on
+// ties. Tenants with queued jobs join the normalised shares even before
+// they have run.
+func (m *Multifactor) order(q *WaitQueue, t time.Time) {
+	maxAge := m.MaxAge
+	if maxAge <= 0 {
+		maxAge = DefaultPriorityMaxAge
+	}
+	maxClass := 0
+	q.Each(func(_ int, w *Workload) bool {
+		if p := w.Priority(); p > maxClass {
+			maxClass = p
+		}
+		if m.FairShare != 0 {
+			m.join(w.Tenant())
+		}
+		return true
+	})
+	fs := map[string]float64{}
+	prio := map[*Workload]float64{}
+	q.Each(func(_ int, w *Workload) bool {
+		age := math.Min(1, math.Max(0, t.Sub(w.SubmitTime).Seconds()/maxAge.Seconds()))
+		p := m.Age * age
+		if m.FairShare != 0 {
+			tenant := w.Tenant()
+			f, ok := fs[tenant]
+			if !ok {
+				f = m.fairShare(tenant, t)
+				fs[tenant] = f
+			}
+			p += m.FairShare * f
+		}
+		if maxClass > 0 && w.Priority() > 0 {
+			p += m.Class * float64(w.Priority()) / float64(maxClass)
+		}
+		prio[w] = p
+		return true
+	})
+	q.Sort(func(a, b *Workload) bool {
+		if prio[a] != prio[b] {
+			return prio[a] > prio[b]
+		}
+		return a.SubmitTime.Before(b.SubmitTime)
+	})
+}
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func near(a, b float64) bool { return math.Abs(a-b) <= 1e-9*math.Max(1, math.Abs(b)) }
+
+func TestFairShareUsageHalfLife(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		halfLife time.Duration
+		run      time.Duration // one job of 2 cores
+		idle     time.Duration // after it stops
+	}{
+		{"default half-life", 0, time.Hour, DefaultUsageHalfLife},
+		{"one hour, one half-life idle", time.Hour, time.Hour, time.Hour},
+		{"one hour, three half-lives idle", time.Hour, 10 * time.Minute, 3 * time.Hour},
+		{"short run, long half-life", 30 * 24 * time.Hour, time.Minute, 15 * 24 * time.Hour},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := &Multifactor{HalfLife: tc.halfLife}
+			m.reset()
+			h := m.halfLife().Seconds()
+			w := Workload{ID: "j", CPU: 2, Labels: map[string]string{"tenant": "a"}}
+			m.started(w, epoch)
+			m.stopped("j", epoch.Add(tc.run))
+
+			// ∫ 2·2^(-(run-s)/h) ds over the run
+			want := 2 * h / math.Ln2 * (1 - math.Exp2(-tc.run.Seconds()/h))
+			if got := m.usage["a"]; !near(got, want) {
+				t.Fatalf("usage after the run %g, want %g", got, want)
+			}
+			m.advance(epoch.Add(tc.run + tc.idle))
+			want *= math.Exp2(-tc.idle.Seconds() / h)
+			if got := m.usage["a"]; !near(got, want) {
+				t.Errorf("usage after %v idle %g, want %g", tc.idle, got, want)
+			}
+		})
+	}
+}
+
+func TestFairShareSteadyState(t *testing.T) {
+	// A tenant using r cores for ever converges to r·h/ln2 core-seconds,
+	// however often usage is brought up to date.
+	h := time.Hour
+	for _, steps := range []int{1, 7, 100} {
+		m := &Multifactor{HalfLife: h}
+		m.reset()
+		m.started(Workload{ID: "j", CPU: 3, Tag: "a"}, epoch)
+		span := 40 * h
+		for i := 1; i <= steps; i++ {
+			m.advance(epoch.Add(span * time.Duration(i) / time.Duration(steps)))
+		}
+		want := 3 * h.Seconds() / math.Ln2 * (1 - math.Exp2(-40))
+		if got := m.usage["a"]; !near(got, want) {
+			t.Errorf("%d steps: usage %g, want %g", steps, got, want)
+		}
+	}
+}
+
+func TestFairShareFactor(t *testing.T) {
+	h := time.Hour
+	job := func(id, tenant string, cpu float64) Workload {
+		return Workload{ID: id, CPU: cpu, Labels: map[string]string{"tenant": tenant}}
+	}
+	for _, tc := range []struct {
+		name   string
+		shares map[string]float64
+		use    func(m *Multifactor) time.Time // returns when to evaluate
+		want   map[string]float64
+	}{
+		{"no usage", nil, func(m *Multifactor) time.Time { return epoch },
+			map[string]float64{"a": 1, "b": 1}},
+		{"one tenant used everything", nil, func(m *Multifactor) time.Time {
+			m.started(job("1", "a", 4), epoch)
+			m.stopped("1", epoch.Add(h))
+			return epoch.Add(h)
+		}, map[string]float64{"a": 0.25, "b": 1}}, // a: 2^(-1/0.5)
+		{"equal usage", nil, func(m *Multifactor) time.Time {
+			m.started(job("1", "a", 2), epoch)
+			m.started(job("2", "b", 2), epoch)
+			m.stopped("1", epoch.Add(h))
+			m.stopped("2", epoch.Add(h))
+			return epoch.Add(h)
+		}, map[string]float64{"a": 0.5, "b": 0.5}},
+		{"usage at its share", map[string]float64{"a": 3, "b": 1}, func(m *Multifactor) time.Time {
+			m.started(job("1", "a", 3), epoch)
+			m.started(job("2", "b", 1), epoch)
+			m.stopped("1", epoch.Add(h))
+			m.stopped("2", epoch.Add(h))
+			return epoch.Add(h)
+		}, map[string]float64{"a": 0.5, "b": 0.5}},
+		{"older usage counts half after a half-life", nil, func(m *Multifactor) time.Time {
+			// a's core-hour is one half-life older than b's, so a holds 1/3
+			// of the decayed usage (0.5 vs 1 on the same curve).
+			m.started(job("1", "a", 1), epoch)
+			m.stopped("1", epoch.Add(time.Minute))
+			m.started(job("2", "b", 1), epoch.Add(h))
+			m.stopped("2", epoch.Add(h+time.Minute))
+			return epoch.Add(h + time.Minute)
+		}, map[string]float64{"a": math.Exp2(-(1.0 / 3) / 0.5), "b": math.Exp2(-(2.0 / 3) / 0.5)}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := &Multifactor{HalfLife: h, Shares: tc.shares}
+			m.reset()
+			at := tc.use(m)
+			for tenant := range tc.want {
+				m.join(tenant) // as if queued
+			}
+			for tenant, want := range tc.want {
+				if got := m.fairShare(tenant, at); !near(got, want) {
+					t.Errorf("fairShare(%s) = %g, want %g", tenant, got, want)
+				}
+			}
+		})
+	}
+}
@@ -10,4 +10,46 @@ type LogEntry struct {
     End    time.Time
     WaitMS int64
     CICost float64
+
+    Deadline time.Time // zero if the job had none
+    DeferMS  int64     // time a Deferrer held the job back on purpose
+
+    CPU       float64 // requested cores
+    Mem       float64 // requested memory
+    Tag       string
+    Tenant    string  // fair-share group (Workload.Tenant)
+    SiteID    string  // site of Node ("" if unassigned)
+    EnergyKWh float64 // IT energy attributed to the job (BaseSim.EnergyCalc)
+
+    PredRuntime time.Duration // runtime policies were told (BaseSim.Runtime); 0 = true duration
+
+    // A preempted job logs one entry per segment it ran (see Preemption).
+    Segment     int           // 0 for the first run, 1 after the first resume, …
+    Preempted   bool          // the segment ended by suspension, not completion
+    Preemptions int           // suspensions of the job up to the end of this segment
+    Restore     time.Duration // restore overhead run at the start of the segment
+    LostWork    time.Duration // run time of the segment past its last checkpoint
+    WastedKWh   float64       // energy of Restore and LostWork (BaseSim.EnergyCalc)
+
+    Executed time.Duration // work a merged job ran (metrics.MergeSegments); 0 = Runtime
+}
+
+// Runtime is the time the job held its node.
+func (e LogEntry) Runtime() time.Duration { return e.End.Sub(e.Start) }
+
+// Work is the run time the job needed: Executed for a merged preempted job,
+// which leaves out suspensions, restores and lost work, else Runtime.
+func (e LogEntry) Work() time.Duration {
+    if e.Executed > 0 {
+        return e.Executed
+    }
+    return e.Runtime()
+}
+
+// Wait is the time between submission and start.
+func (e LogEntry) Wait() time.Duration { return e.Start.Sub(e.Submit) }
+
+// MissedDeadline reports whether the job finished after its deadline.
+func (e LogEntry) MissedDeadline() bool {
+    return !e.Deadline.IsZero() && e.End.After(e.Deadline)
 }
\ No newline at end of file
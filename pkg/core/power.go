@@ -0,0 +1,181 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultPeakPowerW is the peak draw assumed for nodes without a power model
+// or peak_power_w, and DefaultIdleFrac the idle share of their peak.
+const (
+	DefaultPeakPowerW = 400.0
+	DefaultIdleFrac   = 0.15
+)
+
+// PowerModel maps a node's CPU utilisation (0..1) to its draw in watts.
+type PowerModel interface {
+	Power(util float64) float64
+}
+
+// LinearPower draws IdleW at rest and rises linearly to PeakW at full load.
+type LinearPower struct {
+	IdleW, PeakW float64
+}
+
+func (p LinearPower) Power(util float64) float64 {
+	return p.IdleW + clampUtil(util)*math.Max(p.PeakW-p.IdleW, 0)
+}
+
+// String is the ParsePowerModel spec of p.
+func (p LinearPower) String() string {
+	return "linear:" + formatW(p.IdleW) + ":" + formatW(p.PeakW)
+}
+
+// PowerCurve interpolates linearly between measured (utilisation, watts)
+// breakpoints, e.g. the 0%, 10%, …, 100% load levels of a SPECpower_ssj2008
+// report. Outside the breakpoints the end values are held.
+type PowerCurve struct {
+	Util  []float64 // ascending, 0..1
+	Watts []float64
+}
+
+// NewPowerCurve sorts the breakpoints by utilisation. Utilisations above 1
+// are read as percentages.
+func NewPowerCurve(util, watts []float64) (*PowerCurve, error) {
+	if len(util) != len(watts) || len(util) == 0 {
+		return nil, fmt.Errorf("power curve: %d utilisations for %d watt values", len(util), len(watts))
+	}
+	pct := false
+	for _, u := range util {
+		pct = pct || u > 1
+	}
+	idx := make([]int, len(util))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return util[idx[a]] < util[idx[b]] })
+	c := &PowerCurve{Util: make([]float64, len(idx)), Watts: make([]float64, len(idx))}
+	for i, k := range idx {
+		c.Util[i], c.Watts[i] = util[k], watts[k]
+		if pct {
+			c.Util[i] /= 100
+		}
+	}
+	return c, nil
+}
+
+// SPECPower is the curve of a SPECpower-style table: watts at evenly spaced
+// load levels from idle to 100% (11 values for the usual 10% steps).
+func SPECPower(watts ...float64) (*PowerCurve, error) {
+	if len(watts) < 2 {
+		return nil, fmt.Errorf("power curve: need at least idle and full-load watts, got %d values", len(watts))
+	}
+	util := make([]float64, len(watts))
+	for i := range util {
+		util[i] = float64(i) / float64(len(watts)-1)
+	}
+	return NewPowerCurve(util, watts)
+}
+
+func (c *PowerCurve) Power(util float64) float64 {
+	n := len(c.Util)
+	switch {
+	case n == 0:
+		return 0
+	case util <= c.Util[0]:
+		return c.Watts[0]
+	case util >= c.Util[n-1]:
+		return c.Watts[n-1]
+	}
+	i := sort.SearchFloat64s(c.Util, util) // c.Util[i-1] < util <= c.Util[i]
+	u0, u1 := c.Util[i-1], c.Util[i]
+	return c.Watts[i-1] + (util-u0)/(u1-u0)*(c.Watts[i]-c.Watts[i-1])
+}
+
+// String is the ParsePowerModel spec of c ("piecewise:…").
+func (c *PowerCurve) String() string {
+	var b strings.Builder
+	b.WriteString("piecewise")
+	for i := range c.Util {
+		b.WriteString(":" + strconv.FormatFloat(c.Util[i], 'g', 4, 64) + "=" + formatW(c.Watts[i]))
+	}
+	return b.String()
+}
+
+// ParsePowerModel reads a power model spec, in the colon-separated style of
+// ci_profile:
+//
+//	linear:<idle_w>:<peak_w>
+//	spec:<w0>:<w10>:…:<w100>         watts at evenly spaced load levels
+//	piecewise:<u>=<w>:<u>=<w>:…      breakpoints, u in 0..1 or percent
+func ParsePowerModel(s string) (PowerModel, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	switch parts[0] {
+	case "linear":
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("power model %q: want linear:<idle_w>:<peak_w>", s)
+		}
+		vs, err := parseFloats(parts[1:])
+		if err != nil {
+			return nil, fmt.Errorf("power model %q: %w", s, err)
+		}
+		return LinearPower{IdleW: vs[0], PeakW: vs[1]}, nil
+	case "spec":
+		vs, err := parseFloats(parts[1:])
+		if err != nil {
+			return nil, fmt.Errorf("power model %q: %w", s, err)
+		}
+		return SPECPower(vs...)
+	case "piecewise":
+		var us, ws []float64
+		for _, p := range parts[1:] {
+			u, w, ok := strings.Cut(p, "=")
+			if !ok {
+				return nil, fmt.Errorf("power model %q: breakpoint %q is not <u>=<w>", s, p)
+			}
+			vs, err := parseFloats([]string{strings.TrimSuffix(u, "%"), w})
+			if err != nil {
+				return nil, fmt.Errorf("power model %q: %w", s, err)
+			}
+			us, ws = append(us, vs[0]), append(ws, vs[1])
+		}
+		return NewPowerCurve(us, ws)
+	}
+	return nil, fmt.Errorf("unknown power model %q (want linear, spec or piecewise)", s)
+}
+
+// PowerModel returns the node's power model: Power if set, else the default
+// linear model (15% idle) around Metadata["peak_power_w"].
+func (n *SimulatedNode) PowerModel() PowerModel {
+	if n.Power != nil {
+		return n.Power
+	}
+	peak := DefaultPeakPowerW
+	if p, err := strconv.ParseFloat(n.Metadata["peak_power_w"], 64); err == nil && p > 0 {
+		peak = p
+	}
+	return LinearPower{IdleW: peak * DefaultIdleFrac, PeakW: peak}
+}
+
+func parseFloats(ss []string) ([]float64, error) {
+	out := make([]float64, len(ss))
+	for i, s := range ss {
+		v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+func formatW(w float64) string {
+	return strconv.FormatFloat(math.Round(w*10)/10, 'f', -1, 64)
+}
+
+func clampUtil(u float64) float64 {
+	return math.Max(0, math.Min(1, u))
+}
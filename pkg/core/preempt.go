@@ -0,0 +1,240 @@
+package core
+
+import (
+	"sort"
+	"time"
+)
+
+// DefaultPreemptCheck is how often Preemption re-checks node CI.
+const DefaultPreemptCheck = 15 * time.Minute
+
+// Preemption lets BaseSim suspend running jobs labelled preemptible (see
+// Workload.Preemptible) and resume them later, possibly on another node.
+// A suspended job releases its reservation and goes back to the queue with
+// the work its checkpoint did not cover; each segment it runs is logged as
+// its own LogEntry.
+type Preemption struct {
+	// Priority evicts preemptible jobs of lower priority (Workload.Priority)
+	// when a job finds no node with room for it.
+	Priority bool
+
+	// CIThreshold suspends preemptible jobs while their node's CI (CIAt,
+	// gCO₂/kWh) is above it, checked every Check (default 15m); suspended
+	// jobs are held back for Hold (default Check). 0 disables.
+	CIThreshold float64
+	CIAt        func(n *SimulatedNode, t time.Time) float64
+	Check       time.Duration
+	Hold        time.Duration
+
+	// MinRun is how long a segment must run past its restore before a CI
+	// check may suspend it (default Check, and never less than Interval).
+	// Every CI suspension thus keeps at least some work, so jobs finish
+	// even while CI stays above the threshold.
+	MinRun time.Duration
+
+	// Overhead is the checkpoint/restore time added to every resumed
+	// segment. Interval is the periodic checkpoint interval: on suspension
+	// the work since the last checkpoint is lost. 0 checkpoints on
+	// suspension, losing nothing.
+	Overhead time.Duration
+	Interval time.Duration
+}
+
+func (p *Preemption) check() time.Duration {
+	if p.Check > 0 {
+		return p.Check
+	}
+	return DefaultPreemptCheck
+}
+
+func (p *Preemption) minRun() time.Duration {
+	d := p.MinRun
+	if d <= 0 {
+		d = p.check()
+	}
+	if d < p.Interval {
+		d = p.Interval
+	}
+	return d
+}
+
+func (p *Preemption) hold() time.Duration {
+	if p.Hold > 0 {
+		return p.Hold
+	}
+	return p.check()
+}
+
+// segment is a running preemptible job.
+type segment struct {
+	w          Workload // as placed (remaining work + restore overhead)
+	orig       Workload // as submitted
+	node       *SimulatedNode
+	start, end time.Time
+	restart    time.Duration // restore overhead at the start of the segment
+	entry      int           // index into LogsBuf
+}
+
+// jobState is the progress of a job that has been suspended at least once.
+type jobState struct {
+	orig        Workload
+	done        time.Duration // work kept by checkpoints
+	preemptions int
+	suspended   time.Time // last suspension
+}
+
+// suspend checkpoints the running job id, releases its node and queues the
+// remaining work, held back for hold.
+func (b *BaseSim) suspend(id string, hold time.Duration) {
+	p := b.Preempt
+	r := b.running[id]
+	delete(b.running, id)
+	r.node.Evict(id)
+	if b.Priority != nil {
+		b.Priority.stopped(id, b.Clock)
+	}
+
+	st := b.jobs[id]
+	if st == nil {
+		st = &jobState{orig: r.orig}
+		b.jobs[id] = st
+	}
+	elapsed := b.Clock.Sub(r.start)
+	restored := r.restart
+	if restored > elapsed {
+		restored = elapsed
+	}
+	saved := elapsed - restored
+	if p.Interval > 0 {
+		saved = saved.Truncate(p.Interval)
+	}
+	lost := elapsed - restored - saved
+	st.done += saved
+	st.preemptions++
+	st.suspended = b.Clock
+
+	e := &b.LogsBuf[r.entry]
+	e.End = b.Clock
+	e.Preempted = true
+	e.Preemptions = st.preemptions
+	e.Restore = restored
+	e.LostWork = lost
+	ran, wasted := r.w, r.w
+	ran.Duration, wasted.Duration = elapsed, restored+lost
+	if b.CICalc != nil {
+		e.CICost = b.CICalc(r.node, ran, r.start)
+	}
+	if b.EnergyCalc != nil {
+		e.EnergyKWh = b.EnergyCalc(r.node, ran)
+		e.WastedKWh = b.EnergyCalc(r.node, wasted)
+	}
+
+	if hold > 0 {
+		if b.held == nil {
+			b.held = map[string]time.Time{}
+			b.since = map[string]time.Time{}
+		}
+		b.held[id] = b.Clock.Add(hold)
+		b.kernel.At(b.held[id], EventTimer)
+	}
+	rem := st.orig
+	rem.Duration = st.orig.Duration - st.done + p.Overhead
+	b.requeue = append(b.requeue, rem)
+}
+
+// flushRequeue queues the jobs suspended since the last flush.
+func (b *BaseSim) flushRequeue() {
+	for _, w := range b.requeue {
+		b.queue.Push(w)
+	}
+	b.requeue = b.requeue[:0]
+}
+
+// preemptFor evicts lower-priority preemptible jobs to make room for w and
+// returns the node it now fits on (nil if none can be freed). It picks the
+// node needing the fewest evictions, taking the lowest priorities and then
+// the most recently started jobs first.
+func (b *BaseSim) preemptFor(w Workload) *SimulatedNode {
+	byNode := map[*SimulatedNode][]*segment{}
+	for _, r := range b.running {
+		if r.orig.Priority() < w.Priority() {
+			byNode[r.node] = append(byNode[r.node], r)
+		}
+	}
+	var best *SimulatedNode
+	var bestVictims []*segment
+	for _, n := range b.Nodes {
+		vs := byNode[n]
+		if len(vs) == 0 || n.TotalCPU < w.CPU || n.TotalMemory < w.Memory {
+			continue
+		}
+		sort.Slice(vs, func(i, j int) bool {
+			if pi, pj := vs[i].orig.Priority(), vs[j].orig.Priority(); pi != pj {
+				return pi < pj
+			}
+			if !vs[i].start.Equal(vs[j].start) {
+				return vs[i].start.After(vs[j].start)
+			}
+			return vs[i].orig.ID < vs[j].orig.ID
+		})
+		cpu, mem := n.AvailableCPU, n.AvailableMemory
+		k := 0
+		for k < len(vs) && (cpu < w.CPU || mem < w.Memory) {
+			cpu += vs[k].w.CPU
+			mem += vs[k].w.Memory
+			k++
+		}
+		if cpu < w.CPU || mem < w.Memory {
+			continue
+		}
+		if best == nil || k < len(bestVictims) {
+			best, bestVictims = n, vs[:k]
+		}
+	}
+	for _, r := range bestVictims {
+		b.suspend(r.orig.ID, 0)
+	}
+	if len(bestVictims) > 0 {
+		b.kernel.At(b.Clock, EventTimer) // resume the victims elsewhere
+	}
+	return best
+}
+
+// ensureTick schedules the next CI check if CI preemption is on and none
+// is pending.
+func (b *BaseSim) ensureTick() {
+	p := b.Preempt
+	if p == nil || p.CIThreshold <= 0 || p.CIAt == nil || b.tickPending {
+		return
+	}
+	b.tickPending = true
+	b.tickAt = b.Clock.Add(p.check())
+	b.kernel.At(b.tickAt, EventCIChange)
+}
+
+// ciTick suspends the preemptible jobs whose node's CI is above the
+// threshold and that have run for MinRun since their restore, once the
+// pending check is due, and keeps checking while any preemptible job runs.
+func (b *BaseSim) ciTick() {
+	if !b.tickPending || b.Clock.Before(b.tickAt) {
+		return
+	}
+	b.tickPending = false
+	ids := make([]string, 0, len(b.running))
+	for id := range b.running {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		r := b.running[id]
+		if b.Clock.Sub(r.start) < r.restart+b.Preempt.minRun() {
+			continue
+		}
+		if b.Preempt.CIAt(r.node, b.Clock) > b.Preempt.CIThreshold {
+			b.suspend(id, b.Preempt.hold())
+		}
+	}
+	if len(b.running) > 0 {
+		b.ensureTick()
+	}
+}
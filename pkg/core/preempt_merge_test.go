@@ -0,0 +1,86 @@
+package core_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"kube-scheduler/pkg/core"
+	"kube-scheduler/pkg/metrics"
+	"kube-scheduler/pkg/predict"
+)
+
+// anyNode scores every node 0, so BaseSim takes the first that fits.
+type anyNode struct{}
+
+func (anyNode) Name() string { return "any" }
+
+func (anyNode) Score(_ context.Context, _ core.Job, nodes []core.SimulatedNode) (core.Scores, error) {
+	sc := core.Scores{}
+	for _, n := range nodes {
+		sc[n.Name] = 0
+	}
+	return sc, nil
+}
+
+// Suspensions, restores and lost work are not run time the job needed: an
+// oracle predictor scores no error on the merged jobs.
+func TestOraclePredictsMergedPreemptedJobs(t *testing.T) {
+	epoch := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name               string
+		overhead, interval time.Duration
+	}{
+		{"checkpoint on suspend", 0, 0},
+		{"restore overhead", time.Minute, 0},
+		{"overhead and interval", 5 * time.Minute, 10 * time.Minute},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := &core.BaseSim{}
+			b.Init([]*core.SimulatedNode{core.NewNode("n0", 4, 8, 0)}, anyNode{})
+			b.Runtime = predict.Oracle{}
+			b.Preempt = &core.Preemption{
+				CIThreshold: 100,
+				// high CI every other hour
+				CIAt: func(_ *core.SimulatedNode, at time.Time) float64 {
+					if at.Sub(epoch)/time.Hour%2 == 1 {
+						return 500
+					}
+					return 50
+				},
+				Check:    15 * time.Minute,
+				Overhead: tc.overhead,
+				Interval: tc.interval,
+			}
+			durs := map[string]time.Duration{"a": 2 * time.Hour, "b": 3*time.Hour + 20*time.Minute, "c": 30 * time.Minute}
+			for id, d := range durs {
+				b.AddWorkload(core.Workload{ID: id, SubmitTime: epoch, CPU: 1, Memory: 1, Duration: d,
+					Labels: map[string]string{"preemptible": "true"}})
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := b.RunContext(ctx); err != nil {
+				t.Fatalf("RunContext: %v", err)
+			}
+
+			logs := metrics.MergeSegments(b.Logs())
+			if len(logs) != len(durs) {
+				t.Fatalf("merged %d jobs, want %d", len(logs), len(durs))
+			}
+			preempted := 0
+			for _, e := range logs {
+				preempted += e.Preemptions
+				if e.Work() != durs[e.JobID] {
+					t.Errorf("%s: executed %v, want %v (held the node %v)", e.JobID, e.Work(), durs[e.JobID], e.Runtime())
+				}
+			}
+			if preempted == 0 {
+				t.Fatalf("no job was preempted")
+			}
+			if mape := metrics.RuntimePredictionError(logs); math.Abs(mape) > 1e-9 {
+				t.Errorf("oracle MAPE %g over %d preemptions, want 0", mape, preempted)
+			}
+		})
+	}
+}
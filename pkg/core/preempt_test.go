@@ -0,0 +1,88 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// firstNode scores every node 0, so BaseSim takes the first that fits.
+type firstNode struct{}
+
+func (firstNode) Name() string { return "first" }
+
+func (firstNode) Score(_ context.Context, _ Job, nodes []SimulatedNode) (Scores, error) {
+	sc := Scores{}
+	for _, n := range nodes {
+		sc[n.Name] = 0
+	}
+	return sc, nil
+}
+
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestCIPreemptionTerminatesUnderConstantHighCI(t *testing.T) {
+	for _, tc := range []struct {
+		name                      string
+		check, overhead, interval time.Duration
+	}{
+		{"checkpoint on suspend", 15 * time.Minute, 0, 0},
+		{"overhead above check", 15 * time.Minute, 20 * time.Minute, 0},
+		{"interval above check", 15 * time.Minute, 5 * time.Minute, time.Hour},
+		{"overhead and interval above check", 5 * time.Minute, 10 * time.Minute, 30 * time.Minute},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			const dur = 2 * time.Hour
+			b := &BaseSim{}
+			b.Init([]*SimulatedNode{NewNode("n0", 4, 8, 0)}, firstNode{})
+			b.Preempt = &Preemption{
+				CIThreshold: 100,
+				CIAt:        func(*SimulatedNode, time.Time) float64 { return 500 },
+				Check:       tc.check,
+				Overhead:    tc.overhead,
+				Interval:    tc.interval,
+			}
+			b.AddWorkload(Workload{ID: "j", SubmitTime: epoch, CPU: 2, Memory: 2, Duration: dur,
+				Labels: map[string]string{"preemptible": "true"}})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := b.RunContext(ctx); err != nil {
+				t.Fatalf("RunContext: %v", err)
+			}
+			logs := b.Logs()
+			if len(logs) < 2 {
+				t.Fatalf("got %d segments, want the job suspended at least once", len(logs))
+			}
+			kept := time.Duration(0)
+			minRun := b.Preempt.minRun()
+			for i, e := range logs {
+				restart := time.Duration(0)
+				if i > 0 {
+					restart = tc.overhead
+				}
+				if e.Restore != restart {
+					t.Errorf("segment %d restored for %v, want %v", i, e.Restore, restart)
+				}
+				ran := e.End.Sub(e.Start)
+				if e.Preempted {
+					if ran < restart+minRun {
+						t.Errorf("segment %d suspended after %v, before restore %v + min run %v", i, ran, restart, minRun)
+					}
+					if e.Restore+e.LostWork >= ran {
+						t.Errorf("segment %d kept no work (ran %v, restore %v, lost %v)", i, ran, e.Restore, e.LostWork)
+					}
+				} else if i != len(logs)-1 {
+					t.Errorf("segment %d not preempted but not last", i)
+				}
+				kept += ran - e.Restore - e.LostWork
+			}
+			if last := logs[len(logs)-1]; last.Preempted {
+				t.Errorf("last segment was preempted")
+			}
+			if kept != dur {
+				t.Errorf("kept work %v over %d segments, want %v", kept, len(logs), dur)
+			}
+		})
+	}
+}
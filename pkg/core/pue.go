@@ -0,0 +1,88 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PUECurve maps outside temperature (°C) to PUE by linear interpolation
+// between breakpoints, holding the end values, e.g. free cooling up to
+// 15°C and chillers taking over above it.
+type PUECurve struct {
+	TempC []float64 // ascending
+	PUE   []float64
+}
+
+// ParsePUECurve reads "<°C>=<pue>:<°C>=<pue>:…", e.g. "10=1.1:25=1.3:35=1.6".
+func ParsePUECurve(s string) (*PUECurve, error) {
+	type point struct{ t, p float64 }
+	var pts []point
+	for _, part := range strings.Split(strings.TrimSpace(s), ":") {
+		t, p, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("PUE curve %q: breakpoint %q is not <°C>=<pue>", s, part)
+		}
+		vs, err := parseFloats([]string{t, p})
+		if err != nil {
+			return nil, fmt.Errorf("PUE curve %q: %w", s, err)
+		}
+		if vs[1] < 1 {
+			return nil, fmt.Errorf("PUE curve %q: PUE %v below 1", s, vs[1])
+		}
+		pts = append(pts, point{vs[0], vs[1]})
+	}
+	sort.Slice(pts, func(a, b int) bool { return pts[a].t < pts[b].t })
+	c := &PUECurve{}
+	for _, p := range pts {
+		c.TempC = append(c.TempC, p.t)
+		c.PUE = append(c.PUE, p.p)
+	}
+	return c, nil
+}
+
+// At returns the PUE at outside temperature tempC.
+func (c *PUECurve) At(tempC float64) float64 {
+	n := len(c.TempC)
+	switch {
+	case n == 0:
+		return 1
+	case tempC <= c.TempC[0]:
+		return c.PUE[0]
+	case tempC >= c.TempC[n-1]:
+		return c.PUE[n-1]
+	}
+	i := sort.SearchFloat64s(c.TempC, tempC) // c.TempC[i-1] < tempC <= c.TempC[i]
+	t0, t1 := c.TempC[i-1], c.TempC[i]
+	return c.PUE[i-1] + (tempC-t0)/(t1-t0)*(c.PUE[i]-c.PUE[i-1])
+}
+
+// TempPUE is the PUE of a site whose cooling overhead follows the outside
+// temperature.
+type TempPUE struct {
+	Temp  Series // °C
+	Curve *PUECurve
+}
+
+func (p TempPUE) At(t time.Time) float64 { return p.Curve.At(p.Temp.At(t)) }
+
+// PUEAt returns the site's PUE at t: PUESeries if set, else the constant
+// PUE (1 when unset).
+func (s *Site) PUEAt(t time.Time) float64 {
+	if s.PUESeries != nil {
+		return s.PUESeries.At(t)
+	}
+	if s.PUE > 0 {
+		return s.PUE
+	}
+	return 1
+}
+
+// PUEOver is the site's mean PUE over [t, t+d).
+func (s *Site) PUEOver(t time.Time, d time.Duration) float64 {
+	if s.PUESeries == nil {
+		return s.PUEAt(t)
+	}
+	return SeriesMean(s.PUESeries, t, d, 5*time.Minute)
+}
@@ -0,0 +1,99 @@
+package core
+
+import "sort"
+
+// WaitQueue holds submitted-but-unplaced workloads in arrival order.
+// Removing a workload leaves a tombstone, so a scheduling pass can stop
+// early without shifting the tail; tombstones are compacted once they
+// outnumber live entries.
+type WaitQueue struct {
+	items []*Workload
+	head  int // index of the first live entry (or len(items))
+	live  int
+
+	minCPU, minMem float64 // lower bounds on the live requests
+}
+
+func (q *WaitQueue) Len() int { return q.live }
+
+func (q *WaitQueue) Push(w Workload) {
+	if q.live == 0 || w.CPU < q.minCPU {
+		q.minCPU = w.CPU
+	}
+	if q.live == 0 || w.Memory < q.minMem {
+		q.minMem = w.Memory
+	}
+	q.items = append(q.items, &w)
+	q.live++
+}
+
+// MinRequest returns lower bounds on the CPU and memory requested by any
+// queued workload. Bounds only tighten on compaction, so they may be stale
+// (too low) but never too high.
+func (q *WaitQueue) MinRequest() (cpu, mem float64) { return q.minCPU, q.minMem }
+
+// Each visits live entries in order until fn returns false. Remove may be
+// called on the visited index from inside fn.
+func (q *WaitQueue) Each(fn func(i int, w *Workload) bool) {
+	for i := q.head; i < len(q.items); i++ {
+		if q.items[i] == nil {
+			continue
+		}
+		if !fn(i, q.items[i]) {
+			return
+		}
+	}
+}
+
+// Remove tombstones the entry at index i.
+func (q *WaitQueue) Remove(i int) {
+	if q.items[i] == nil {
+		return
+	}
+	q.items[i] = nil
+	q.live--
+	for q.head < len(q.items) && q.items[q.head] == nil {
+		q.head++
+	}
+	if dead := len(q.items) - q.live; dead > 64 && dead > q.live {
+		q.compact()
+	}
+}
+
+// Items returns the live workloads in order.
+func (q *WaitQueue) Items() []Workload {
+	out := make([]Workload, 0, q.live)
+	q.Each(func(_ int, w *Workload) bool {
+		out = append(out, *w)
+		return true
+	})
+	return out
+}
+
+// Sort reorders the live entries by less, keeping the order of equal ones.
+func (q *WaitQueue) Sort(less func(a, b *Workload) bool) {
+	q.compact()
+	sort.SliceStable(q.items, func(i, j int) bool { return less(q.items[i], q.items[j]) })
+}
+
+func (q *WaitQueue) compact() {
+	out := q.items[:0]
+	q.minCPU, q.minMem = 0, 0
+	for _, w := range q.items[q.head:] {
+		if w == nil {
+			continue
+		}
+		if len(out) == 0 || w.CPU < q.minCPU {
+			q.minCPU = w.CPU
+		}
+		if len(out) == 0 || w.Memory < q.minMem {
+			q.minMem = w.Memory
+		}
+		out = append(out, w)
+	}
+	for i := len(out); i < len(q.items); i++ {
+		q.items[i] = nil
+	}
+	q.items = out
+	q.head = 0
+}
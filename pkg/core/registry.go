@@ -0,0 +1,201 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ParamKind is the type of a policy parameter.
+type ParamKind int
+
+const (
+	ParamFloat ParamKind = iota
+	ParamInt
+	ParamBool
+	ParamString
+	ParamDuration
+)
+
+func (k ParamKind) String() string {
+	switch k {
+	case ParamFloat:
+		return "float"
+	case ParamInt:
+		return "int"
+	case ParamBool:
+		return "bool"
+	case ParamString:
+		return "string"
+	case ParamDuration:
+		return "duration"
+	}
+	return "unknown"
+}
+
+// ParamSpec documents one parameter of a registered policy.
+type ParamSpec struct {
+	Name    string
+	Kind    ParamKind
+	Default string // parsed like a user value
+	Doc     string
+}
+
+// Params holds parsed parameter values keyed by name.
+type Params map[string]any
+
+func (p Params) Float(name string) float64 { v, _ := p[name].(float64); return v }
+func (p Params) Int(name string) int       { v, _ := p[name].(int); return v }
+func (p Params) Bool(name string) bool     { v, _ := p[name].(bool); return v }
+func (p Params) String(name string) string { v, _ := p[name].(string); return v }
+func (p Params) Duration(name string) time.Duration {
+	v, _ := p[name].(time.Duration)
+	return v
+}
+
+// PolicyFactory builds a Policy from typed parameters. Model packages
+// register one per scheduler in init(), so importing a model makes it
+// available by name.
+type PolicyFactory struct {
+	Name   string
+	Doc    string
+	Params []ParamSpec
+
+	// CIWeight names the parameter a carbon-weight sweep sets ("" = none).
+	CIWeight string
+	// Shift runs the policy with BaseSim.Shift (temporal shifting) enabled.
+	Shift bool
+
+	New func(p Params) (Policy, error)
+}
+
+// NodeBinder is implemented by policies that need the node set they will
+// schedule on (e.g. to build per-site queue or forecast state).
+type NodeBinder interface {
+	BindNodes(nodes []*SimulatedNode)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]PolicyFactory{}
+)
+
+// RegisterPolicy makes a factory available by name. It panics if the name is
+// empty or already registered.
+func RegisterPolicy(f PolicyFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if f.Name == "" || f.New == nil {
+		panic("core: RegisterPolicy needs a name and a constructor")
+	}
+	if _, dup := registry[f.Name]; dup {
+		panic("core: RegisterPolicy called twice for " + f.Name)
+	}
+	registry[f.Name] = f
+}
+
+// LookupPolicy returns the factory registered under name.
+func LookupPolicy(name string) (PolicyFactory, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	f, ok := registry[name]
+	return f, ok
+}
+
+// PolicyNames lists the registered policies in sorted order.
+func PolicyNames() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Param returns the spec of the named parameter.
+func (f PolicyFactory) Param(name string) (ParamSpec, bool) {
+	for _, ps := range f.Params {
+		if ps.Name == name {
+			return ps, true
+		}
+	}
+	return ParamSpec{}, false
+}
+
+// Defaults returns every parameter at its default value.
+func (f PolicyFactory) Defaults() Params {
+	p := Params{}
+	for _, ps := range f.Params {
+		if err := f.Set(p, ps.Name, ps.Default); err != nil {
+			panic(fmt.Sprintf("core: %s: bad default for %s: %v", f.Name, ps.Name, err))
+		}
+	}
+	return p
+}
+
+// Set parses value according to the parameter's kind and stores it in p.
+func (f PolicyFactory) Set(p Params, name, value string) error {
+	ps, ok := f.Param(name)
+	if !ok {
+		return fmt.Errorf("%s has no parameter %q", f.Name, name)
+	}
+	value = strings.TrimSpace(value)
+	var (
+		v   any
+		err error
+	)
+	switch ps.Kind {
+	case ParamFloat:
+		v, err = strconv.ParseFloat(value, 64)
+	case ParamInt:
+		v, err = strconv.Atoi(value)
+	case ParamBool:
+		v, err = strconv.ParseBool(value)
+	case ParamDuration:
+		v, err = time.ParseDuration(value)
+	default:
+		v = value
+	}
+	if err != nil {
+		return fmt.Errorf("%s.%s: invalid %s %q", f.Name, name, ps.Kind, value)
+	}
+	p[name] = v
+	return nil
+}
+
+// ParseParams applies "k=v,k=v" overrides on top of the defaults.
+func (f PolicyFactory) ParseParams(s string) (Params, error) {
+	p := f.Defaults()
+	for _, kv := range strings.Split(s, ",") {
+		if kv = strings.TrimSpace(kv); kv == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s: expected key=value, got %q", f.Name, kv)
+		}
+		if err := f.Set(p, strings.TrimSpace(k), v); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// Usage describes the factory and its parameters for -help style listings.
+func (f PolicyFactory) Usage() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s\n", f.Name, f.Doc)
+	for _, ps := range f.Params {
+		mark := ""
+		if ps.Name == f.CIWeight {
+			mark = " (swept by -ci-weights)"
+		}
+		fmt.Fprintf(&b, "    %-16s %-8s default %-6s %s%s\n", ps.Name, ps.Kind, ps.Default, ps.Doc, mark)
+	}
+	return b.String()
+}
@@ -2,17 +2,22 @@ package core
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
 )
 
 type Scores map[string]float64 // Lower is better.
 
-// ArgMin picks the node ID with minimum score.
+// ArgMin picks the node ID with minimum score. Ties go to the smallest ID so
+// that map iteration order never changes a placement.
 func ArgMin(sc Scores) (string, bool) {
 	best := ""
 	bestV := 0.0
 	ok := false
 	for id, v := range sc {
-		if !ok || v< bestV {
+		if !ok || v < bestV || (v == bestV && id < best) {
 			best, bestV, ok = id, v, true
 		}
 	}
@@ -21,36 +26,208 @@ func ArgMin(sc Scores) (string, bool) {
 
 type Scheduler interface {
 	Name() string
-	Score(ctx context.Context, job Job, nodes []Node) (Scores, error) // Score each candidate 
+	Score(ctx context.Context, job Job, nodes []Node) (Scores, error) // Score each candidate
 	Select(Scores) (string, bool)
 }
 
-// func (s *Scheduler) scoreJobOnNode(j Job, n Node, now time.Time) float64 {
-//     // 1) Forecasted/observed CI for node's site
-//     ci := s.ci.Forecast(n.SiteID, now, j.EstimatedDuration) // gCO2/kWh time series (avg/area)
-//     // 2) Energy integral (estimator) and site normalisation
-//     eJ := s.energy.EstimateJoules(j, n)                     // ∫ P_j dt (J)
-//     ciCost := (eJ/3.6e6) * ci * n.Site.PUE * n.Site.K       // -> grams CO2
-//     // 3) Delay/queue proxies
-//     wait := s.queue.EstimatedStartDelay(n.SiteID, j, now).Seconds()
-//     qlen := s.queue.Length(n.SiteID)
-//     // 4) Optional price/repro terms (placeholders if unused)
-//     price := s.price.Estimate(n.SiteID, j)
-//     repro := s.repro.Penalty(j, n)
-//     // 5) Weighted sum (lower is better)
-//     return s.W.Carbon*ciCost + s.W.Wait*wait + s.W.Queue*float64(qlen) +
-//            s.W.Price*price + s.W.Repro*repro
-// }
-
-// func (s *Scheduler) SelectSiteAndNode(j core.Job, now time.Time) (siteID, nodeID string, ok bool) {
-//     best := math.Inf(1)
-//     for _, site := range s.Sites {
-//         if !s.queue.HasCapacity(site.ID, j, now) { continue }
-//         cand := s.bestNodeAtSite(j, site, now)    // evaluates scoreJobOnNode
-//         if cand.found && cand.score < best {
-//             best, siteID, nodeID = cand.score, site.ID, cand.node.ID
-//             ok = true
-//         }
-//     }
-//     return
-// }
\ No newline at end of file
+// SiteWeights weight the terms of the multi-site score (lower is better).
+type SiteWeights struct {
+	Carbon float64 // grams CO₂ of the job on the node
+	Wait   float64 // estimated start delay at the site (s)
+	Queue  float64 // site queue length
+	Price  float64 // electricity cost of the job (€)
+	Repro  float64 // reproducibility penalty
+}
+
+// SiteScheduler is the two-level (site, then node) weighted-sum scheduler.
+// Every estimator is optional; a nil estimator contributes nothing.
+type SiteScheduler struct {
+	Sites []*Site // evaluation order; derived from Nodes when empty
+	Nodes []Node  // candidate nodes, matched to sites by SiteID
+	W     SiteWeights
+
+	CI     CIForecaster
+	Energy EnergyEstimator
+	Queue  QueueEstimator
+	Price  PriceEstimator
+	Repro  ReproEstimator
+
+	Clock Clock // time source for SelectCluster; nil means WallClock
+}
+
+func (s *SiteScheduler) Name() string { return "site" }
+
+// scoreJobOnNode is the weighted sum of carbon, wait, queue, price and
+// reproducibility terms for running j on n starting now.
+func (s *SiteScheduler) scoreJobOnNode(j Job, n Node, now time.Time) float64 {
+	// 1) Forecasted/observed CI for node's site (gCO2/kWh over the job window)
+	ci := math.NaN()
+	if s.CI != nil {
+		ci = s.CI.Forecast(n.SiteID, now, j.EstimatedDuration)
+		if math.IsNaN(ci) {
+			ci = s.CI.Forecast(n.ID, now, j.EstimatedDuration)
+		}
+	}
+	if math.IsNaN(ci) {
+		ci = n.Metrics["ci_g_per_kwh"]
+	}
+	// 2) Energy integral (estimator) and site normalisation (PUE over the job window)
+	eJ := 0.0
+	if s.Energy != nil {
+		eJ = s.Energy.EstimateJoules(j, n) // ∫ P_j dt (J)
+	}
+	pue, k := 1.0, 1.0
+	if n.Site != nil {
+		pue = n.Site.PUEOver(now, time.Duration(j.EstimatedDuration*float64(time.Second)))
+		if n.Site.K > 0 {
+			k = n.Site.K
+		}
+	}
+	facilityKWh := (eJ / 3.6e6) * pue * k
+	ciCost := facilityKWh * ci // -> grams CO2
+	// 3) Delay/queue proxies
+	wait, qlen := 0.0, 0
+	if s.Queue != nil {
+		wait = s.Queue.EstimatedStartDelay(n.SiteID, j, now).Seconds()
+		qlen = s.Queue.Length(n.SiteID)
+	}
+	// 4) Optional price/repro terms
+	price, repro := 0.0, 0.0
+	if s.Price != nil {
+		price = facilityKWh * s.Price.Estimate(n.SiteID, j, now) // -> €
+	}
+	if s.Repro != nil {
+		repro = s.Repro.Penalty(j, n)
+	}
+	// 5) Weighted sum (lower is better)
+	return s.W.Carbon*ciCost + s.W.Wait*wait + s.W.Queue*float64(qlen) +
+		s.W.Price*price + s.W.Repro*repro
+}
+
+// bestNodeAtSite returns the best-scoring node of the site that fits j.
+func (s *SiteScheduler) bestNodeAtSite(j Job, siteID string, nodes []Node, now time.Time) (Node, float64, bool) {
+	var best Node
+	bestScore := math.Inf(1)
+	found := false
+	for _, n := range nodes {
+		if n.SiteID != siteID || !n.Fits(j) {
+			continue
+		}
+		sc := s.scoreJobOnNode(j, n, now)
+		if !found || sc < bestScore || (sc == bestScore && n.ID < best.ID) {
+			best, bestScore, found = n, sc, true
+		}
+	}
+	return best, bestScore, found
+}
+
+// SelectSiteAndNode picks the site with the best candidate node, skipping
+// sites the queue estimator reports as full.
+func (s *SiteScheduler) SelectSiteAndNode(j Job, now time.Time) (siteID, nodeID string, ok bool) {
+	return s.selectFrom(j, s.Nodes, now)
+}
+
+func (s *SiteScheduler) selectFrom(j Job, nodes []Node, now time.Time) (siteID, nodeID string, ok bool) {
+	best := math.Inf(1)
+	for _, site := range s.siteIDs(nodes) {
+		if s.Queue != nil && !s.Queue.HasCapacity(site, j, now) {
+			continue
+		}
+		cand, score, found := s.bestNodeAtSite(j, site, nodes, now)
+		if found && score < best {
+			best, siteID, nodeID = score, site, cand.ID
+			ok = true
+		}
+	}
+	return
+}
+
+// Score implements Scheduler: every node at an admissible site that fits j
+// gets its scoreJobOnNode value, so ArgMin selects the same node as
+// SelectSiteAndNode.
+func (s *SiteScheduler) Score(ctx context.Context, j Job, nodes []Node) (Scores, error) {
+	now := Now(ctx)
+	sc := Scores{}
+	for _, site := range s.siteIDs(nodes) {
+		if s.Queue != nil && !s.Queue.HasCapacity(site, j, now) {
+			continue
+		}
+		for _, n := range nodes {
+			if n.SiteID == site && n.Fits(j) {
+				sc[n.ID] = s.scoreJobOnNode(j, n, now)
+			}
+		}
+	}
+	return sc, nil
+}
+
+func (s *SiteScheduler) Select(sc Scores) (string, bool) { return ArgMin(sc) }
+
+// siteIDs lists s.Sites in order, or the distinct SiteIDs of nodes.
+func (s *SiteScheduler) siteIDs(nodes []Node) []string {
+	var ids []string
+	if len(s.Sites) > 0 {
+		for _, site := range s.Sites {
+			ids = append(ids, site.ID)
+		}
+		return ids
+	}
+	seen := map[string]bool{}
+	for _, n := range nodes {
+		if !seen[n.SiteID] {
+			seen[n.SiteID] = true
+			ids = append(ids, n.SiteID)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// SelectCluster implements SchedulingStrategy so the site scheduler can run
+// behind CentralUnit: every cluster that accepts w is one site holding a
+// single node, with the cluster's CarbonIntensity as its CI and (unless an
+// Energy estimator is set) EstimateEnergyCost taken as the energy in kWh.
+func (s *SiteScheduler) SelectCluster(clusters []Cluster, w WorkloadTestbed) (Cluster, string, error) {
+	j := Job{ID: w.ID, CPUReq: float64(w.CPURequirement)}
+	byName := map[string]Cluster{}
+	var nodes []Node
+	for _, c := range clusters {
+		if !c.CanAccept(w) {
+			continue
+		}
+		byName[c.Name()] = c
+		nodes = append(nodes, Node{
+			ID:     c.Name(),
+			SiteID: c.Name(),
+			CPUCap: j.CPUReq,
+			Metrics: map[string]float64{
+				"ci_g_per_kwh": c.CarbonIntensity(),
+				"energy_kwh":   c.EstimateEnergyCost(w),
+			},
+		})
+	}
+	sub := *s
+	sub.Sites = nil
+	if sub.Energy == nil {
+		sub.Energy = clusterEnergy{}
+	}
+	now := time.Now()
+	if s.Clock != nil {
+		now = s.Clock.Now()
+	}
+	_, id, ok := sub.selectFrom(j, nodes, now)
+	if !ok {
+		return nil, "No cluster can accept job", fmt.Errorf("no cluster can accept job")
+	}
+	for _, n := range nodes {
+		if n.ID == id {
+			return byName[id], fmt.Sprintf("Site scheduler selected %s (score: %.2f)", id, sub.scoreJobOnNode(j, n, now)), nil
+		}
+	}
+	return byName[id], "", nil
+}
+
+// clusterEnergy reads the per-cluster estimate stashed by SelectCluster.
+type clusterEnergy struct{}
+
+func (clusterEnergy) EstimateJoules(_ Job, n Node) float64 { return n.Metrics["energy_kwh"] * 3.6e6 }
@@ -0,0 +1,15 @@
+package core
+
+import (
+	"context"
+	"time"
+)
+
+// Deferrer is implemented by policies that can shift a job in time. BaseSim
+// consults it (when Shift is enabled) before placing a job that could start
+// now.
+type Deferrer interface {
+	// Defer returns the time at which j should be reconsidered, or ok=false
+	// to place it immediately. BaseSim clamps the answer to j.LatestStart().
+	Defer(ctx context.Context, j Job, nodes []SimulatedNode) (until time.Time, ok bool)
+}
@@ -2,9 +2,40 @@ package core
 
 type Site struct {
     ID       string
-    PUE      float64   // PUE_s
+    PUE      float64   // PUE_s (nominal; see PUEAt)
     K        float64   // k_s (metering calibration)
     CIRegion string    // region/grid id for forecasts
+    CI       *Trace    // CI trace of CIRegion (gCO₂/kWh); nil → node ci_profile
+    MarginalCI *Trace  // marginal emissions of CIRegion (gCO₂/kWh); nil → CI
+    Marginal   bool    // the run's emissions mode: score and account with MarginalCI
+    Tariff   string    // flat:/tou: spec or price-trace region ("" → CIRegion)
+    Price    Series    // electricity price (€/kWh); nil → free
+    PUEModel string    // "trace:<region>" or "temp:<region>:<°C>=<pue>:…" ("" → constant PUE)
+    PUESeries Series   // PUE over time (PUE trace or TempPUE); nil → PUE
+
+    // On-site supply, drawn before the grid (see metrics.Account).
+    RenewableRegion string   // generation trace region ("" → none)
+    RenewableKW     float64  // installed capacity; trace rescaled to peak at it (0 → trace in kW)
+    Renewable       Series   // generation (kW); nil → none
+    Battery         *Battery // nil → none
+}
+
+// ActiveCI is the trace of the run's emissions mode: MarginalCI when
+// Marginal is set and the region has one, else CI.
+func (s *Site) ActiveCI() *Trace {
+    if s.Marginal && s.MarginalCI != nil {
+        return s.MarginalCI
+    }
+    return s.CI
+}
+
+// Battery is a site's on-site storage. It charges from renewable surplus
+// only and discharges before the grid is used.
+type Battery struct {
+    CapacityKWh float64
+    RateKW      float64 // charge and discharge limit (0 → unlimited)
+    Efficiency  float64 // round-trip, lost on charge (0 → 1)
+    InitialSoC  float64 // charge at the start of a run, fraction of capacity
 }
 
 type Node struct {
@@ -15,5 +46,11 @@ type Node struct {
 	Labels  map[string]string
 	SiteID  string
 	Site	   *Site               // Injected pointer
+	Power   PowerModel          // nil → linear around Metrics["peak_power_w"]
     
-}
\ No newline at end of file
+}
+// Fits reports whether j's requests fit the node's free capacity, taken from
+// Metrics["cpu_used"]/["mem_used"] (zero when absent).
+func (n Node) Fits(j Job) bool {
+	return n.CPUCap-n.Metrics["cpu_used"] >= j.CPUReq && n.MemCap-n.Metrics["mem_used"] >= j.MemReq
+}
@@ -0,0 +1,84 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Constant is a Series with the same value at every time (e.g. a flat tariff).
+type Constant float64
+
+func (c Constant) At(time.Time) float64 { return float64(c) }
+
+// TimeOfUse is a daily tariff (€/kWh): Rates[i] applies from hour-of-day
+// Hours[i] (UTC) until the next boundary, and the last rate wraps past
+// midnight until the first boundary.
+type TimeOfUse struct {
+	Hours []float64 // ascending, 0 ≤ h < 24
+	Rates []float64
+}
+
+func (t TimeOfUse) At(at time.Time) float64 {
+	if len(t.Rates) == 0 {
+		return 0
+	}
+	u := at.UTC()
+	h := float64(u.Hour()) + float64(u.Minute())/60 + float64(u.Second())/3600
+	i := sort.SearchFloat64s(t.Hours, h+1e-9) - 1 // last boundary at or before h
+	if i < 0 {
+		i = len(t.Rates) - 1
+	}
+	return t.Rates[i]
+}
+
+// ParseTariff reads an inline price spec (€/kWh):
+//
+//	flat:<price>
+//	tou:<hour>=<price>:<hour>=<price>:…   e.g. tou:0=0.12:7=0.25:22=0.12
+//
+// Day-ahead prices are loaded as traces instead (see loader).
+func ParseTariff(s string) (Series, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	switch parts[0] {
+	case "flat":
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("tariff %q: want flat:<price>", s)
+		}
+		v, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("tariff %q: %w", s, err)
+		}
+		return Constant(v), nil
+	case "tou":
+		type band struct{ h, p float64 }
+		var bands []band
+		for _, p := range parts[1:] {
+			h, v, ok := strings.Cut(p, "=")
+			if !ok {
+				return nil, fmt.Errorf("tariff %q: band %q is not <hour>=<price>", s, p)
+			}
+			vs, err := parseFloats([]string{h, v})
+			if err != nil {
+				return nil, fmt.Errorf("tariff %q: %w", s, err)
+			}
+			if vs[0] < 0 || vs[0] >= 24 {
+				return nil, fmt.Errorf("tariff %q: hour %v outside 0..24", s, vs[0])
+			}
+			bands = append(bands, band{vs[0], vs[1]})
+		}
+		if len(bands) == 0 {
+			return nil, fmt.Errorf("tariff %q: no bands", s)
+		}
+		sort.Slice(bands, func(a, b int) bool { return bands[a].h < bands[b].h })
+		t := TimeOfUse{}
+		for _, b := range bands {
+			t.Hours = append(t.Hours, b.h)
+			t.Rates = append(t.Rates, b.p)
+		}
+		return t, nil
+	}
+	return nil, fmt.Errorf("unknown tariff %q (want flat or tou, or a price trace region)", s)
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"sort"
+	"time"
+)
+
+// Series is anything that yields a value at a point in simulated time
+// (CI traces, stochastic CI processes, tariffs, ...).
+type Series interface {
+	At(t time.Time) float64
+}
+
+// Trace is a sampled time series (e.g. hourly or 5-minute grid carbon
+// intensity). Lookups interpolate linearly between samples and wrap around
+// past either end, so a one-week trace can drive a month-long simulation.
+type Trace struct {
+	Times  []time.Time
+	Values []float64
+	Period time.Duration // wrap-around period: span plus one sample interval
+	Hold   bool          // step function (hold previous sample) instead of linear
+}
+
+// NewTrace sorts the samples by time and derives the wrap-around period.
+func NewTrace(times []time.Time, values []float64) *Trace {
+	idx := make([]int, len(times))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return times[idx[a]].Before(times[idx[b]]) })
+	tr := &Trace{Times: make([]time.Time, len(idx)), Values: make([]float64, len(idx))}
+	for i, k := range idx {
+		tr.Times[i], tr.Values[i] = times[k], values[k]
+	}
+	if n := len(tr.Times); n > 1 {
+		span := tr.Times[n-1].Sub(tr.Times[0])
+		tr.Period = span + span/time.Duration(n-1)
+	}
+	return tr
+}
+
+// At returns the trace value at t.
+func (tr *Trace) At(t time.Time) float64 {
+	n := len(tr.Values)
+	switch {
+	case n == 0:
+		return 0
+	case n == 1 || tr.Period <= 0:
+		return tr.Values[0]
+	}
+	t0 := tr.Times[0]
+	off := t.Sub(t0) % tr.Period
+	if off < 0 {
+		off += tr.Period
+	}
+	at := t0.Add(off)
+
+	// last sample at or before 'at'
+	i := sort.Search(n, func(k int) bool { return tr.Times[k].After(at) }) - 1
+	if i < 0 {
+		i = 0
+	}
+	if tr.Hold {
+		return tr.Values[i]
+	}
+	nextT, nextV := t0.Add(tr.Period), tr.Values[0] // wrap to the first sample
+	if i+1 < n {
+		nextT, nextV = tr.Times[i+1], tr.Values[i+1]
+	}
+	span := nextT.Sub(tr.Times[i])
+	if span <= 0 {
+		return tr.Values[i]
+	}
+	frac := float64(at.Sub(tr.Times[i])) / float64(span)
+	return tr.Values[i] + frac*(nextV-tr.Values[i])
+}
+
+// Mean returns the average of the samples (0 for an empty trace).
+func (tr *Trace) Mean() float64 {
+	if len(tr.Values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range tr.Values {
+		sum += v
+	}
+	return sum / float64(len(tr.Values))
+}
+
+// Range returns the smallest and largest samples (0, 0 for an empty trace).
+func (tr *Trace) Range() (lo, hi float64) {
+	for i, v := range tr.Values {
+		if i == 0 || v < lo {
+			lo = v
+		}
+		if i == 0 || v > hi {
+			hi = v
+		}
+	}
+	return lo, hi
+}
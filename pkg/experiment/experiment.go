@@ -0,0 +1,338 @@
+// Package experiment describes a simulation sweep declaratively: inputs,
+// policies with parameter grids, seeds, repetitions, output location and the
+// summary metrics to report. cmd/run_sim executes a Spec and copies it into
+// the results folder, so a figure can be regenerated from one file.
+package experiment
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"kube-scheduler/pkg/core"
+)
+
+// Spec is the JSON experiment file.
+type Spec struct {
+	Name string `json:"name,omitempty"`
+
+	Inputs   Inputs   `json:"inputs"`
+	Workload Workload `json:"workload,omitempty"`
+
+	// Forecaster is a forecast.Parse spec handed to forecast-aware policies.
+	Forecaster string `json:"forecaster,omitempty"`
+
+	// Emissions selects the CI that policies and co2_g use: "average"
+	// (default) or "marginal", from the marginal column of the CI traces.
+	Emissions string `json:"emissions,omitempty"`
+
+	// Predictor is a predict.Parse spec: the job runtimes policies see
+	// (empty = the true durations).
+	Predictor string `json:"predictor,omitempty"`
+
+	// Priority orders the queue by multifactor job priority with fair share
+	// between tenants (nil = submission order).
+	Priority *Priority `json:"priority,omitempty"`
+
+	// Preemption lets jobs labelled preemptible be suspended and resumed
+	// (nil = never).
+	Preemption *Preemption `json:"preemption,omitempty"`
+
+	// Queues are the queue disciplines every policy runs under (see
+	// core.ParseQueueDiscipline; default first_fit), unless it sets its own.
+	Queues []string `json:"queues,omitempty"`
+
+	CIWeights  []float64 `json:"ci_weights"`  // swept into each policy's CIWeight param
+	BatchSizes []int     `json:"batch_sizes"` // BaseSim scheduling batch sizes
+	Policies   []Policy  `json:"policies"`
+
+	Seeds       []int64 `json:"seeds"`                 // default [1]
+	Repetitions int     `json:"repetitions,omitempty"` // runs per seed; default 1
+
+	OutputDir string   `json:"output_dir,omitempty"` // default "results"
+	Metrics   []string `json:"metrics,omitempty"`    // summary columns; empty = all
+	UtilStep  string   `json:"util_step,omitempty"`  // e.g. "5m": write per-run utilisation series
+}
+
+// Inputs are the files a run reads. Empty node/workload paths are generated
+// under config/ (workloads from the run seed).
+type Inputs struct {
+	Nodes     string   `json:"nodes,omitempty"`
+	Workloads string   `json:"workloads,omitempty"`
+	Sites     string   `json:"sites,omitempty"`
+	CITraces  []string `json:"ci_traces,omitempty"`
+
+	PriceTraces     []string `json:"price_traces,omitempty"`     // electricity prices, matched by site tariff/ci_region
+	RenewableTraces []string `json:"renewable_traces,omitempty"` // on-site generation, matched by site renewable region
+	PUETraces       []string `json:"pue_traces,omitempty"`       // PUE over time, for sites with pue_model trace:<region>
+	TempTraces      []string `json:"temp_traces,omitempty"`      // outside temperature, for sites with pue_model temp:<region>:…
+
+	PowerCurves string `json:"power_curves,omitempty"` // per-node-type curves (model,util,watts)
+}
+
+// Workload transforms applied after loading.
+type Workload struct {
+	DurScale      float64   `json:"dur_scale,omitempty"`      // multiply durations; 0 = 1
+	DurationsS    []float64 `json:"durations_s,omitempty"`    // override durations round-robin
+	DeadlineSlack float64   `json:"deadline_slack,omitempty"` // deadline = duration*(1+slack) for jobs without one
+	Start         string    `json:"start,omitempty"`          // RFC 3339 first submission of generated workloads; default generator.DefaultEpoch
+}
+
+// Priority configures core.Multifactor; durations are Go durations.
+type Priority struct {
+	Age       float64            `json:"age,omitempty"`
+	FairShare float64            `json:"fairshare,omitempty"`
+	Class     float64            `json:"class,omitempty"`
+	MaxAge    string             `json:"max_age,omitempty"`
+	HalfLife  string             `json:"half_life,omitempty"`
+	Shares    map[string]float64 `json:"shares,omitempty"` // tenant -> share (default 1)
+}
+
+// Build returns the core.Multifactor.
+func (p *Priority) Build() (*core.Multifactor, error) {
+	out := &core.Multifactor{Age: p.Age, FairShare: p.FairShare, Class: p.Class, Shares: p.Shares}
+	for _, d := range []struct {
+		name string
+		s    string
+		dst  *time.Duration
+	}{
+		{"max_age", p.MaxAge, &out.MaxAge},
+		{"half_life", p.HalfLife, &out.HalfLife},
+	} {
+		if d.s == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.s)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("priority %s: invalid duration %q", d.name, d.s)
+		}
+		*d.dst = v
+	}
+	return out, nil
+}
+
+// Preemption configures core.Preemption; durations are Go durations such
+// as "15m".
+type Preemption struct {
+	Priority           bool    `json:"priority,omitempty"`     // evict lower-priority jobs for jobs that do not fit
+	CIThreshold        float64 `json:"ci_threshold,omitempty"` // suspend while node CI is above (gCO₂/kWh)
+	Check              string  `json:"check,omitempty"`
+	Hold               string  `json:"hold,omitempty"`
+	MinRun             string  `json:"min_run,omitempty"` // run before a CI suspension; default check
+	CheckpointOverhead string  `json:"checkpoint_overhead,omitempty"`
+	CheckpointInterval string  `json:"checkpoint_interval,omitempty"`
+}
+
+// Build returns the core.Preemption; the caller sets CIAt.
+func (p *Preemption) Build() (*core.Preemption, error) {
+	out := &core.Preemption{Priority: p.Priority, CIThreshold: p.CIThreshold}
+	for _, d := range []struct {
+		name string
+		s    string
+		dst  *time.Duration
+	}{
+		{"check", p.Check, &out.Check},
+		{"hold", p.Hold, &out.Hold},
+		{"min_run", p.MinRun, &out.MinRun},
+		{"checkpoint_overhead", p.CheckpointOverhead, &out.Overhead},
+		{"checkpoint_interval", p.CheckpointInterval, &out.Interval},
+	} {
+		if d.s == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.s)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("preemption %s: invalid duration %q", d.name, d.s)
+		}
+		*d.dst = v
+	}
+	return out, nil
+}
+
+// Policy selects a registered scheduler. Params fix values; Grid sweeps the
+// cartesian product of the listed values. Label names the variants in the
+// output (default: the scheduler name). Queues overrides Spec.Queues.
+type Policy struct {
+	Name   string           `json:"name"`
+	Label  string           `json:"label,omitempty"`
+	Params map[string]any   `json:"params,omitempty"`
+	Grid   map[string][]any `json:"grid,omitempty"`
+	Queues []string         `json:"queues,omitempty"`
+}
+
+// Variant is one fully parameterised policy of the sweep.
+type Variant struct {
+	Label    string
+	Factory  core.PolicyFactory
+	Params   core.Params
+	Explicit map[string]bool // set by Params/Grid, so not overridden by CIWeights
+	Queue    core.QueueDiscipline
+}
+
+// Load reads a Spec and returns it with the raw bytes (for copying into the
+// results folder). Unknown fields are rejected so typos do not pass silently.
+func Load(path string) (*Spec, []byte, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	var s Spec
+	if err := dec.Decode(&s); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	s.Defaults()
+	return &s, raw, nil
+}
+
+// Defaults fills unset fields.
+func (s *Spec) Defaults() {
+	if len(s.CIWeights) == 0 {
+		s.CIWeights = []float64{1.0}
+	}
+	if len(s.BatchSizes) == 0 {
+		s.BatchSizes = []int{100}
+	}
+	if len(s.Seeds) == 0 {
+		s.Seeds = []int64{1}
+	}
+	if s.Repetitions <= 0 {
+		s.Repetitions = 1
+	}
+	if s.OutputDir == "" {
+		s.OutputDir = "results"
+	}
+	if s.Workload.DurScale == 0 {
+		s.Workload.DurScale = 1
+	}
+}
+
+// Marshal renders the spec as indented JSON.
+func (s *Spec) Marshal() []byte {
+	b, _ := json.MarshalIndent(s, "", "  ")
+	return append(b, '\n')
+}
+
+// Variants resolves every policy against the registry and expands its grid
+// and queue disciplines. Grid keys are expanded in sorted order; labels of
+// grid variants carry the swept values, e.g. "ci_aware[util=0.1,wait=0.3]",
+// and a policy run under several disciplines adds "queue=easy" etc.
+func (s *Spec) Variants() ([]Variant, error) {
+	var out []Variant
+	labels := map[string]bool{}
+	for _, p := range s.Policies {
+		f, ok := core.LookupPolicy(p.Name)
+		if !ok {
+			return nil, fmt.Errorf("unknown scheduler %q (registered: %s)", p.Name, strings.Join(core.PolicyNames(), ", "))
+		}
+		base := f.Defaults()
+		explicit := map[string]bool{}
+		for k, v := range p.Params {
+			if err := f.Set(base, k, fmt.Sprint(v)); err != nil {
+				return nil, err
+			}
+			explicit[k] = true
+		}
+		keys := make([]string, 0, len(p.Grid))
+		for k, vs := range p.Grid {
+			if len(vs) == 0 {
+				return nil, fmt.Errorf("%s: empty grid for %q", p.Name, k)
+			}
+			keys = append(keys, k)
+			explicit[k] = true
+		}
+		sort.Strings(keys)
+
+		label := p.Label
+		if label == "" {
+			label = p.Name
+		}
+		qnames := p.Queues
+		if len(qnames) == 0 {
+			qnames = s.Queues
+		}
+		if len(qnames) == 0 {
+			qnames = []string{""}
+		}
+		queues := make([]core.QueueDiscipline, len(qnames))
+		for i, q := range qnames {
+			var err error
+			if queues[i], err = core.ParseQueueDiscipline(q); err != nil {
+				return nil, fmt.Errorf("%s: %w", p.Name, err)
+			}
+		}
+		// cartesian product, last key varying fastest
+		idx := make([]int, len(keys))
+		for {
+			params := core.Params{}
+			for k, v := range base {
+				params[k] = v
+			}
+			var tags []string
+			for i, k := range keys {
+				v := fmt.Sprint(p.Grid[k][idx[i]])
+				if err := f.Set(params, k, v); err != nil {
+					return nil, err
+				}
+				tags = append(tags, k+"="+v)
+			}
+			for _, q := range queues {
+				qtags := tags
+				if len(queues) > 1 {
+					qtags = append(append([]string(nil), tags...), "queue="+q.String())
+				}
+				name := label
+				if len(qtags) > 0 {
+					name += "[" + strings.Join(qtags, ",") + "]"
+				}
+				if labels[name] {
+					return nil, fmt.Errorf("duplicate policy label %q (set \"label\")", name)
+				}
+				labels[name] = true
+				out = append(out, Variant{Label: name, Factory: f, Params: params, Explicit: explicit, Queue: q})
+			}
+
+			i := len(idx) - 1
+			for ; i >= 0; i-- {
+				if idx[i]++; idx[i] < len(p.Grid[keys[i]]) {
+					break
+				}
+				idx[i] = 0
+			}
+			if i < 0 {
+				break
+			}
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no policies")
+	}
+	return out, nil
+}
+
+// WithCIWeight returns the variant's params with its CIWeight parameter set
+// to w, unless the spec fixed that parameter itself.
+func (v Variant) WithCIWeight(w float64) core.Params {
+	p := core.Params{}
+	for k, val := range v.Params {
+		p[k] = val
+	}
+	if ci := v.Factory.CIWeight; ci != "" && !v.Explicit[ci] {
+		p[ci] = w
+	}
+	return p
+}
+
+// RunSeed is the seed of repetition rep of seed: the seed itself for the
+// first repetition, a derived seed for the others.
+func RunSeed(seed int64, rep int) int64 {
+	if rep == 0 {
+		return seed
+	}
+	return core.SeedFor(seed, fmt.Sprintf("rep%d", rep))
+}
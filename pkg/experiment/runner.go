@@ -0,0 +1,87 @@
+package experiment
+
+import (
+	"context"
+	"sync"
+)
+
+// Task is one independent run of a sweep: a policy variant at one CI
+// weight, batch size and (seed, repetition).
+type Task struct {
+	Index    int // position in Spec.Tasks order
+	Variant  Variant
+	CIWeight float64
+	Batch    int
+	Seed     int64 // spec seed
+	Rep      int
+	RunSeed  int64 // RunSeed(Seed, Rep): workloads, CI processes, forecast noise
+}
+
+// Tasks lists the sweep in its canonical order (seed, repetition, CI weight,
+// batch size, variant). The run seed depends only on (seed, repetition), so
+// every policy of a repetition sees the same workload and CI paths.
+func (s *Spec) Tasks(variants []Variant) []Task {
+	var out []Task
+	for _, seed := range s.Seeds {
+		for rep := 0; rep < s.Repetitions; rep++ {
+			for _, ciW := range s.CIWeights {
+				for _, bs := range s.BatchSizes {
+					for _, v := range variants {
+						out = append(out, Task{
+							Index:    len(out),
+							Variant:  v,
+							CIWeight: ciW,
+							Batch:    bs,
+							Seed:     seed,
+							Rep:      rep,
+							RunSeed:  RunSeed(seed, rep),
+						})
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+// RunPool executes fn for every task on up to workers goroutines. Once ctx
+// is done no further tasks start; fn receives ctx so long runs can stop
+// too. progress, if set, is called after each task that ran, serialised.
+// RunPool returns ctx.Err() when the sweep was cut short.
+func RunPool(ctx context.Context, workers int, tasks []Task, fn func(ctx context.Context, t Task) error, progress func(done int, t Task, err error)) error {
+	if workers < 1 {
+		workers = 1
+	}
+	ch := make(chan Task)
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		done int
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range ch {
+				err := fn(ctx, t)
+				mu.Lock()
+				done++
+				if progress != nil {
+					progress(done, t, err)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+feed:
+	for _, t := range tasks {
+		select {
+		case <-ctx.Done():
+			break feed
+		case ch <- t:
+		}
+	}
+	close(ch)
+	wg.Wait()
+	return ctx.Err()
+}
@@ -0,0 +1,203 @@
+// Package forecast predicts the mean carbon intensity over a job's execution
+// window from the CI signal observed so far, so policies can score on
+// expected rather than instantaneous intensity.
+package forecast
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"kube-scheduler/pkg/core"
+	"kube-scheduler/pkg/metrics"
+)
+
+// Series is the ground-truth CI signal (gCO₂/kWh) a forecaster observes.
+type Series = func(time.Time) float64
+
+// Forecaster predicts the mean of s over [start, start+d), using only values
+// of s at or before issued (except the day-ahead and oracle baselines, which
+// model an external forecast service).
+type Forecaster interface {
+	Name() string
+	Forecast(s Series, issued, start time.Time, d time.Duration) float64
+}
+
+// defaultStep is the sampling interval for window means.
+const defaultStep = 5 * time.Minute
+
+// WindowMean averages s over [start, start+d) sampled every step.
+func WindowMean(s Series, start time.Time, d, step time.Duration) float64 {
+	if d <= 0 {
+		return s(start)
+	}
+	if step <= 0 {
+		step = defaultStep
+	}
+	if n := d / step; n > 1000 {
+		step = d / 1000
+	}
+	sum, n := 0.0, 0
+	for t := start; t.Before(start.Add(d)); t = t.Add(step) {
+		sum += s(t)
+		n++
+	}
+	return sum / float64(n)
+}
+
+// NodeSeries exposes a simulated node's CI (site trace, stochastic process or
+// ci_profile) as a Series.
+func NodeSeries(n *core.SimulatedNode) Series {
+	return func(t time.Time) float64 { return metrics.CIAt(n, t) }
+}
+
+// Persistence assumes the current value holds for the whole window.
+type Persistence struct{}
+
+func (Persistence) Name() string { return "persistence" }
+func (Persistence) Forecast(s Series, issued, _ time.Time, _ time.Duration) float64 {
+	return s(issued)
+}
+
+// SeasonalNaive repeats the most recent observed season (default one day):
+// each instant is predicted by the value a whole number of periods earlier,
+// at or before the issue time.
+type SeasonalNaive struct {
+	Period time.Duration
+	Step   time.Duration
+}
+
+func (f SeasonalNaive) Name() string { return "seasonal" }
+func (f SeasonalNaive) Forecast(s Series, issued, start time.Time, d time.Duration) float64 {
+	period := f.Period
+	if period <= 0 {
+		period = 24 * time.Hour
+	}
+	past := func(t time.Time) float64 {
+		if t.After(issued) {
+			k := (t.Sub(issued) + period - 1) / period
+			t = t.Add(-k * period)
+		}
+		return s(t)
+	}
+	return WindowMean(past, start, d, f.Step)
+}
+
+// EWMA smooths the recent history (Lookback, sampled every Step) with weight
+// Alpha on the newest sample and predicts the resulting level.
+type EWMA struct {
+	Alpha    float64
+	Step     time.Duration
+	Lookback time.Duration
+}
+
+func (f EWMA) Name() string { return "ewma" }
+func (f EWMA) Forecast(s Series, issued, _ time.Time, _ time.Duration) float64 {
+	alpha, step, lookback := f.Alpha, f.Step, f.Lookback
+	if alpha <= 0 || alpha > 1 {
+		alpha = 0.3
+	}
+	if step <= 0 {
+		step = 15 * time.Minute
+	}
+	if lookback <= 0 {
+		lookback = 6 * time.Hour
+	}
+	t := issued.Add(-lookback)
+	level := s(t)
+	for t = t.Add(step); !t.After(issued); t = t.Add(step) {
+		level = alpha*s(t) + (1-alpha)*level
+	}
+	return level
+}
+
+// DayAhead models a published day-ahead forecast: the true window mean with a
+// multiplicative Gaussian error of relative standard deviation Sigma. The
+// error is deterministic in (Seed, issue hour, window) so runs reproduce.
+type DayAhead struct {
+	Sigma float64
+	Seed  int64
+	Step  time.Duration
+}
+
+func (f DayAhead) Name() string { return "dayahead" }
+func (f DayAhead) Forecast(s Series, issued, start time.Time, d time.Duration) float64 {
+	truth := WindowMean(s, start, d, f.Step)
+	if f.Sigma <= 0 {
+		return truth
+	}
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d|%d|%d|%d|%x", f.Seed, issued.Unix()/3600, start.Unix()/3600, int64(d/time.Minute), math.Float64bits(truth))
+	z := rand.New(rand.NewSource(int64(h.Sum64()))).NormFloat64()
+	return math.Max(0, truth*(1+f.Sigma*z))
+}
+
+// Oracle returns the true window mean (perfect foresight upper bound).
+type Oracle struct{ Step time.Duration }
+
+func (Oracle) Name() string { return "oracle" }
+func (f Oracle) Forecast(s Series, _, start time.Time, d time.Duration) float64 {
+	return WindowMean(s, start, d, f.Step)
+}
+
+// Parse builds a forecaster from a flag value:
+//
+//	persistence | seasonal[:<periodHours>] | ewma[:<alpha>] | dayahead[:<sigma>] | oracle
+//
+// The seed only affects dayahead.
+func Parse(spec string, seed int64) (Forecaster, error) {
+	parts := strings.Split(strings.TrimSpace(spec), ":")
+	arg := func(def float64) (float64, error) {
+		if len(parts) < 2 || parts[1] == "" {
+			return def, nil
+		}
+		return strconv.ParseFloat(parts[1], 64)
+	}
+	switch parts[0] {
+	case "", "none":
+		return nil, nil
+	case "persistence":
+		return Persistence{}, nil
+	case "seasonal":
+		h, err := arg(24)
+		return SeasonalNaive{Period: time.Duration(h * float64(time.Hour))}, err
+	case "ewma":
+		a, err := arg(0.3)
+		return EWMA{Alpha: a}, err
+	case "dayahead":
+		sigma, err := arg(0.1)
+		return DayAhead{Sigma: sigma, Seed: seed}, err
+	case "oracle":
+		return Oracle{}, nil
+	}
+	return nil, fmt.Errorf("unknown forecaster %q", spec)
+}
+
+// Provider answers keyed queries (site or node ID) in the shape the
+// multi-site scheduler expects: mean CI over the next durationSec seconds.
+type Provider struct {
+	F      Forecaster
+	Series map[string]Series
+}
+
+func (p *Provider) Forecast(key string, now time.Time, durationSec float64) float64 {
+	s, ok := p.Series[key]
+	if !ok {
+		return math.NaN()
+	}
+	d := time.Duration(durationSec * float64(time.Second))
+	if p.F == nil {
+		return s(now)
+	}
+	return p.F.Forecast(s, now, now, d)
+}
+
+// Aware is implemented by policies that can score on a forecast; runners
+// hand them the configured Forecaster after construction.
+type Aware interface {
+	SetForecaster(f Forecaster)
+}
@@ -7,8 +7,15 @@ import (
     "os"
     "time"
     "path/filepath"
+
+    "kube-scheduler/pkg/core"
 )
 
+// DefaultEpoch is the first submission time of generated workloads unless
+// the caller's clock says otherwise. CI traces and profiles are evaluated at
+// absolute time, so a fixed epoch keeps same-seed runs identical.
+var DefaultEpoch = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
 // NodeSpec represents one machine in the cluster
 type NodeSpec struct {
     Name      string
@@ -62,11 +69,11 @@ func GenerateNodes(path string) error {
             "4","8","static:100",
         })
     }
-    // medium: volatile CI
+    // medium: volatile CI (mean-reverting around 150)
     for i:=0; i<3; i++ {
         w.Write([]string{
             fmt.Sprintf("med-%d",i),
-            "8","16","static:150",  // we can add variation
+            "8","16","ou:150:0.1:15:300:50:300",
         })
     }
     // burstable: sine wave CI
@@ -83,8 +90,9 @@ func GenerateNodes(path string) error {
     return nil
 }
 
-// GenerateWorkloads writes a CSV of {id,submit,cpu,mem,duration,tag}
-func GenerateWorkloads(path string, seed int64) error {
+// GenerateWorkloads writes a CSV of {id,submit,cpu,mem,duration,tag}; the
+// submissions start at clk.Now() (nil = DefaultEpoch), never wall time.
+func GenerateWorkloads(path string, seed int64, clk core.Clock) error {
 //     rand.Seed(seed)
 //     file, _ := os.Create(path)
 //     w := csv.NewWriter(file)
@@ -109,7 +117,10 @@ func GenerateWorkloads(path string, seed int64) error {
     }
 
     rand.Seed(seed)
-    now := time.Now()
+    now := DefaultEpoch
+    if clk != nil {
+        now = clk.Now()
+    }
     for i:=0; i<1000; i++ {
         // pick a type
         typ := rand.Intn(4)
@@ -0,0 +1,203 @@
+package loader
+
+import (
+	"encoding/csv"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"kube-scheduler/pkg/core"
+)
+
+// lbsPerMWhToGPerKWh converts WattTime's lbs/MWh into gCO₂/kWh.
+const lbsPerMWhToGPerKWh = 453.59237 / 1000.0
+
+var traceTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// LoadCITracesFromCSV reads a carbon-intensity trace export and returns one
+// trace per region. Columns are found by header name (case-insensitive), so
+// both ElectricityMaps and WattTime exports load as-is:
+//
+//	time:   "Datetime (UTC)", "datetime", "timestamp", "point_time", "time"
+//	region: "Zone Id", "zone_id", "region", "ba", "zone", "Country"
+//	value:  "Carbon Intensity gCO₂eq/kWh (direct)", "carbon_intensity", "ci", "value"
+//
+// Files without a region column are keyed by their base name (e.g.
+// "NL.csv" → "NL"). A "units"/"unit" column mentioning lbs converts
+// WattTime lbs/MWh into gCO₂/kWh.
+func LoadCITracesFromCSV(path string) map[string]*core.Trace {
+	traces, _ := loadTraceColumn(path, []string{
+		"carbon intensity gco₂eq/kwh (direct)",
+		"carbon intensity gco2eq/kwh (direct)",
+		"carbon_intensity_direct",
+		"carbon_intensity",
+		"carbonintensity",
+		"ci",
+		"value",
+	}, true)
+	return traces
+}
+
+// marginalCICols name marginal-emissions columns, e.g. WattTime's MOER or
+// ElectricityMaps' marginal signal.
+var marginalCICols = []string{
+	"marginal carbon intensity gco₂eq/kwh",
+	"marginal carbon intensity gco2eq/kwh",
+	"marginal_carbon_intensity",
+	"marginal_ci",
+	"moer",
+	"marginal",
+}
+
+// LoadMarginalCITracesFromCSV reads the marginal-emissions column of a CI
+// trace export (see marginalCICols), by region as in LoadCITracesFromCSV.
+// Files without one return no traces.
+func LoadMarginalCITracesFromCSV(path string) map[string]*core.Trace {
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("LoadMarginalCITracesFromCSV: open %s: %v", path, err)
+	}
+	header, err := csv.NewReader(f).Read()
+	f.Close()
+	if err != nil || findColumn(header, marginalCICols...) < 0 {
+		return nil
+	}
+	traces, _ := loadTraceColumn(path, marginalCICols, false)
+	return traces
+}
+
+// loadTraceColumn groups (time, value) samples of the first matching value
+// column by region and returns them with that column's (normalised) header.
+// Substring matches on "carbon intensity" are accepted when carbonFallback
+// is set, so LCA-only exports still load.
+func loadTraceColumn(path string, valueCols []string, carbonFallback bool) (map[string]*core.Trace, string) {
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("loadTraceColumn: open %s: %v", path, err)
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err != nil {
+		log.Fatalf("loadTraceColumn: read header of %s: %v", path, err)
+	}
+
+	timeCol := findColumn(header, "datetime (utc)", "datetime", "timestamp", "point_time", "time", "date")
+	regionCol := findColumn(header, "zone id", "zone_id", "region", "ba", "zone", "country")
+	valueCol := findColumn(header, valueCols...)
+	if valueCol < 0 && carbonFallback {
+		for i, h := range header {
+			if strings.Contains(normHeader(h), "carbon intensity") {
+				valueCol = i
+				break
+			}
+		}
+	}
+	unitCol := findColumn(header, "units", "unit")
+	if timeCol < 0 || valueCol < 0 {
+		log.Fatalf("loadTraceColumn: %s: need a time and a value column, got %v", path, header)
+	}
+
+	defRegion := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	times := map[string][]time.Time{}
+	values := map[string][]float64{}
+	var order []string
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatalf("loadTraceColumn: read record: %v", err)
+		}
+		if timeCol >= len(rec) || valueCol >= len(rec) || rec[valueCol] == "" {
+			continue
+		}
+		t, ok := parseTraceTime(rec[timeCol])
+		if !ok {
+			continue
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(rec[valueCol]), 64)
+		if err != nil {
+			continue
+		}
+		if unitCol >= 0 && unitCol < len(rec) && strings.Contains(strings.ToLower(rec[unitCol]), "lbs") {
+			v *= lbsPerMWhToGPerKWh
+		}
+		region := defRegion
+		if regionCol >= 0 && regionCol < len(rec) && rec[regionCol] != "" {
+			region = rec[regionCol]
+		}
+		if _, seen := times[region]; !seen {
+			order = append(order, region)
+		}
+		times[region] = append(times[region], t)
+		values[region] = append(values[region], v)
+	}
+
+	out := make(map[string]*core.Trace, len(order))
+	for _, region := range order {
+		out[region] = core.NewTrace(times[region], values[region])
+	}
+	return out, normHeader(header[valueCol])
+}
+
+// AttachCITraces points every site at the trace of its CIRegion.
+func AttachCITraces(sites map[string]*core.Site, traces map[string]*core.Trace) {
+	for _, s := range sites {
+		if tr, ok := traces[s.CIRegion]; ok && s.CIRegion != "" {
+			s.CI = tr
+		}
+	}
+}
+
+// AttachMarginalCITraces points every site at the marginal-emissions trace
+// of its CIRegion, if there is one.
+func AttachMarginalCITraces(sites map[string]*core.Site, traces map[string]*core.Trace) {
+	for _, s := range sites {
+		if tr, ok := traces[s.CIRegion]; ok && s.CIRegion != "" {
+			s.MarginalCI = tr
+		}
+	}
+}
+
+func findColumn(header []string, names ...string) int {
+	for _, name := range names {
+		for i, h := range header {
+			if normHeader(h) == name {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func normHeader(h string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+}
+
+func parseTraceTime(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range traceTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	if sec, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Unix(0, int64(sec*1e9)).UTC(), true
+	}
+	return time.Time{}, false
+}
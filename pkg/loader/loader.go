@@ -20,11 +20,17 @@ import (
 //   - "static:<value>"
 //   - "sine:<mean>:<amp>:<periodSec>"
 //   - "randwalk:<min>:<max>:<stepSec>"
+//   - "ou:<mean>:<theta>:<sigma>:<stepSec>[:<min>:<max>]"
 //
 // For now we stow the profile string in the node’s Metadata
 // and set CarbonIntensity to the “mean” value; the CIScheduler
 // wrapper can look at Metadata to fetch a dynamic CI per tick.
-// header: name,cpu,mem,ci_profile[,site_id[,peak_power_w]]
+// header: name,cpu,mem,ci_profile[,site_id[,peak_power_w[,power_model[,embodied_kg[,lifetime_y]]]]]
+//
+// power_model names a curve from LoadPowerCurvesFromCSV or gives one inline
+// ("linear:<idle_w>:<peak_w>", "spec:<w0>:…:<w100>", "piecewise:<u>=<w>:…");
+// AttachPowerModels resolves it. embodied_kg is the node's manufacturing
+// footprint (kgCO₂e) and lifetime_y the years it is amortised over.
 func LoadNodesFromCSV(path string) []*core.SimulatedNode {
     f, err := os.Open(path); if err != nil { log.Fatalf("open %s: %v", path, err) }
     defer f.Close()
@@ -57,6 +63,8 @@ func LoadNodesFromCSV(path string) []*core.SimulatedNode {
             minv, _ := strconv.ParseFloat(parts[1], 64)
             maxv, _ := strconv.ParseFloat(parts[2], 64)
             baseCI = (minv + maxv) / 2.0
+        case "ou":
+            baseCI, _ = strconv.ParseFloat(parts[1], 64)
         default:
             // leave baseCI at 0 if unknown
         }
@@ -72,18 +80,59 @@ func LoadNodesFromCSV(path string) []*core.SimulatedNode {
             if n.Metadata == nil { n.Metadata = map[string]string{} }
             n.Metadata["peak_power_w"] = rec[5]
         }
+        if len(rec) >= 7 && rec[6] != "" {
+            n.Metadata["power_model"] = rec[6]
+        }
+        if len(rec) >= 8 && rec[7] != "" {
+            n.EmbodiedKg, _ = strconv.ParseFloat(rec[7], 64)
+        }
+        if len(rec) >= 9 && rec[8] != "" {
+            years, _ := strconv.ParseFloat(rec[8], 64)
+            n.Lifetime = time.Duration(years * 365 * 24 * float64(time.Hour))
+        }
         nodes = append(nodes, n)
     }
     return nodes
 }
 
 
+// AttachCIProcesses gives every node with a "randwalk" or "ou" ci_profile a
+// seeded stochastic CI process (see core.RandomWalk). Each node's seed is
+// derived from seed and its name; all paths start at origin, so the same
+// (seed, origin) reproduces the same CI realisation in every run.
+func AttachCIProcesses(nodes []*core.SimulatedNode, seed int64, origin time.Time) {
+    for _, n := range nodes {
+        parts := strings.Split(n.Metadata["ci_profile"], ":")
+        f := func(i int) float64 {
+            if i >= len(parts) { return 0 }
+            v, _ := strconv.ParseFloat(parts[i], 64)
+            return v
+        }
+        var rw *core.RandomWalk
+        switch parts[0] {
+        case "randwalk":
+            rw = core.NewRandomWalk(f(1), f(2), time.Duration(f(3))*time.Second, core.SeedFor(seed, n.Name))
+        case "ou":
+            rw = core.NewOU(f(1), f(2), f(3), time.Duration(f(4))*time.Second, core.SeedFor(seed, n.Name))
+            if len(parts) >= 7 {
+                rw.Min, rw.Max = f(5), f(6)
+            }
+        default:
+            continue
+        }
+        rw.Origin = origin
+        n.CISeries = rw
+    }
+}
+
 // LoadWorkloadsFromCSV parses a CSV of:
 //
-//    id,submit,cpu,mem,duration,tag
+//    id,submit,cpu,mem,duration,tag[,deadline[,labels]]
 //
 // and returns a slice of Workload with SubmitTime, Duration,
-// CPU, Memory and Tag populated.
+// CPU, Memory and Tag populated. The optional deadline column is
+// seconds after submit by which the job must finish; labels are
+// "k=v;k=v" pairs, e.g. "preemptible=true;priority=2".
 func LoadWorkloadsFromCSV(path string) []core.Workload {
     f, err := os.Open(path)
     if err != nil {
@@ -114,6 +163,21 @@ func LoadWorkloadsFromCSV(path string) []core.Workload {
         if len(rec) >= 6 {
             tag = rec[5]
         }
+        var deadline time.Duration
+        if len(rec) >= 7 && rec[6] != "" {
+            dl, _ := strconv.ParseFloat(rec[6], 64)
+            deadline = time.Duration(dl * float64(time.Second))
+        }
+        var labels map[string]string
+        if len(rec) >= 8 && rec[7] != "" {
+            labels = map[string]string{}
+            for _, kv := range strings.Split(rec[7], ";") {
+                k, v, _ := strings.Cut(kv, "=")
+                if k = strings.TrimSpace(k); k != "" {
+                    labels[k] = strings.TrimSpace(v)
+                }
+            }
+        }
 
         wls = append(wls, core.Workload{
             ID:         id,
@@ -122,6 +186,8 @@ func LoadWorkloadsFromCSV(path string) []core.Workload {
             CPU:        cpuF,
             Memory:     memF,
             Tag:        tag,
+            Deadline:   deadline,
+            Labels:     labels,
         })
     }
     return wls
@@ -0,0 +1,98 @@
+package loader
+
+import (
+	"encoding/csv"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"kube-scheduler/pkg/core"
+)
+
+// LoadPowerCurvesFromCSV reads per-node-type power curves, one breakpoint
+// per row:
+//
+//	model,util,watts
+//	r640,0,58.4
+//	r640,10,97.2
+//	...
+//
+// Columns are found by header name ("model"/"type"/"name", "util"/"load",
+// "watts"/"power_w"/"power"). Utilisations are 0..1 or percent (with or
+// without "%"), as in SPECpower reports. Curves are keyed by model and
+// referenced from the power_model column of the nodes CSV.
+func LoadPowerCurvesFromCSV(path string) map[string]core.PowerModel {
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("LoadPowerCurvesFromCSV: open %s: %v", path, err)
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	header, err := r.Read()
+	if err != nil {
+		log.Fatalf("LoadPowerCurvesFromCSV: %s: read header: %v", path, err)
+	}
+	mCol := findColumn(header, "model", "type", "node_type", "name")
+	uCol := findColumn(header, "util", "utilisation", "utilization", "load")
+	wCol := findColumn(header, "watts", "power_w", "power", "avg_power_w")
+	if mCol < 0 || uCol < 0 || wCol < 0 {
+		log.Fatalf("LoadPowerCurvesFromCSV: %s: need model, util and watts columns, got %v", path, header)
+	}
+
+	var order []string
+	utils := map[string][]float64{}
+	watts := map[string][]float64{}
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatalf("LoadPowerCurvesFromCSV: %s: %v", path, err)
+		}
+		model := strings.TrimSpace(rec[mCol])
+		u, err1 := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(rec[uCol]), "%"), 64)
+		w, err2 := strconv.ParseFloat(strings.TrimSpace(rec[wCol]), 64)
+		if err1 != nil || err2 != nil {
+			log.Fatalf("LoadPowerCurvesFromCSV: %s: bad row %v", path, rec)
+		}
+		if _, seen := utils[model]; !seen {
+			order = append(order, model)
+		}
+		utils[model] = append(utils[model], u)
+		watts[model] = append(watts[model], w)
+	}
+
+	out := make(map[string]core.PowerModel, len(order))
+	for _, model := range order {
+		c, err := core.NewPowerCurve(utils[model], watts[model])
+		if err != nil {
+			log.Fatalf("LoadPowerCurvesFromCSV: %s: %s: %v", path, model, err)
+		}
+		out[model] = c
+	}
+	return out
+}
+
+// AttachPowerModels resolves each node's power_model (see LoadNodesFromCSV):
+// the name of a curve in curves, or an inline core.ParsePowerModel spec.
+// Nodes without one keep the default linear model around peak_power_w.
+func AttachPowerModels(nodes []*core.SimulatedNode, curves map[string]core.PowerModel) {
+	for _, n := range nodes {
+		spec := n.Metadata["power_model"]
+		if spec == "" {
+			continue
+		}
+		if c, ok := curves[spec]; ok {
+			n.Power = c
+			continue
+		}
+		pm, err := core.ParsePowerModel(spec)
+		if err != nil {
+			log.Fatalf("AttachPowerModels: node %s: %v", n.Name, err)
+		}
+		n.Power = pm
+	}
+}
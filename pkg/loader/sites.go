@@ -2,12 +2,27 @@ package loader
 
 import (
 	"encoding/csv"
+	"log"
+	"math"
 	"os"
 	"strconv"
+	"strings"
 
 	"kube-scheduler/pkg/core"
 )
 
+// LoadSitesFromCSV parses a CSV of:
+//
+//   site_id,pue,k,ci_region[,tariff[,renewable,renewable_kw[,battery_kwh,battery_kw,battery_eff[,battery_soc[,pue_model]]]]]
+//
+// where tariff is an inline price (see core.ParseTariff) or the region of a
+// price trace; AttachPrices resolves it. renewable names the region of an
+// on-site generation trace (see AttachRenewables) and renewable_kw its
+// installed capacity. A site with battery_kwh > 0 gets a battery charging
+// and discharging at up to battery_kw (empty = unlimited) with round-trip
+// efficiency battery_eff (empty = 1), starting battery_soc full (0..1).
+// pue_model makes the PUE time-varying (see AttachPUE); pue stays the
+// nominal value.
 func LoadSitesFromCSV(path string) map[string]*core.Site {
 	f, err := os.Open(path); if err != nil { panic(err) }
 	defer f.Close()
@@ -23,10 +38,46 @@ func LoadSitesFromCSV(path string) map[string]*core.Site {
 		k, _ := strconv.ParseFloat(row[2], 64) 
 		region := row[3]
 		sites[id] = &core.Site{ID: id, PUE: pue, K: k, CIRegion: region}
+		if len(row) >= 5 {
+			sites[id].Tariff = strings.TrimSpace(row[4])
+		}
+		if len(row) >= 7 {
+			sites[id].RenewableRegion = strings.TrimSpace(row[5])
+			sites[id].RenewableKW = optFloat(path, id, "renewable_kw", row[6])
+		}
+		if len(row) >= 10 {
+			if capKWh := optFloat(path, id, "battery_kwh", row[7]); capKWh > 0 {
+				b := &core.Battery{
+					CapacityKWh: capKWh,
+					RateKW:      optFloat(path, id, "battery_kw", row[8]),
+					Efficiency:  optFloat(path, id, "battery_eff", row[9]),
+				}
+				if len(row) >= 11 {
+					b.InitialSoC = optFloat(path, id, "battery_soc", row[10])
+				}
+				sites[id].Battery = b
+			}
+		}
+		if len(row) >= 12 {
+			sites[id].PUEModel = strings.TrimSpace(row[11])
+		}
 	}
 	return sites
 }
 
+// optFloat parses an optional numeric site column (empty = 0).
+func optFloat(path, site, col, v string) float64 {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		log.Fatalf("LoadSitesFromCSV: %s: site %s: %s %q: %v", path, site, col, v, err)
+	}
+	return f
+}
+
 func AttachSites(nodes []*core.SimulatedNode, sites map[string]*core.Site) {
 	for _, n := range nodes {
 		if n.Site == nil && n.SiteID != "" {
@@ -35,4 +86,177 @@ func AttachSites(nodes []*core.SimulatedNode, sites map[string]*core.Site) {
 			}
 		}
 	}
-}
\ No newline at end of file
+}
+// LoadPriceTracesFromCSV reads electricity price traces (e.g. an ENTSO-E
+// day-ahead export) by region, with the same time and region columns as
+// LoadCITracesFromCSV. Prices are returned in €/kWh; columns whose header
+// mentions MWh are converted.
+func LoadPriceTracesFromCSV(path string) map[string]*core.Trace {
+	traces, col := loadTraceColumn(path, []string{
+		"price_eur_per_kwh",
+		"price_eur_kwh",
+		"day-ahead price [eur/mwh]",
+		"day-ahead price (eur/mwh)",
+		"price_eur_per_mwh",
+		"price_eur_mwh",
+		"price",
+		"value",
+	}, false)
+	if strings.Contains(col, "mwh") {
+		for _, tr := range traces {
+			for i := range tr.Values {
+				tr.Values[i] /= 1000
+			}
+		}
+	}
+	return traces
+}
+
+// AttachPrices gives every site its electricity price: the inline tariff
+// if its Tariff is one, otherwise the trace of the Tariff region (default
+// CIRegion). Sites without either stay free.
+func AttachPrices(sites map[string]*core.Site, traces map[string]*core.Trace) {
+	for _, s := range sites {
+		if strings.HasPrefix(s.Tariff, "flat:") || strings.HasPrefix(s.Tariff, "tou:") {
+			p, err := core.ParseTariff(s.Tariff)
+			if err != nil {
+				log.Fatalf("AttachPrices: site %s: %v", s.ID, err)
+			}
+			s.Price = p
+			continue
+		}
+		region := s.Tariff
+		if region == "" {
+			region = s.CIRegion
+		}
+		if tr, ok := traces[region]; ok && region != "" {
+			s.Price = tr
+		} else if s.Tariff != "" {
+			log.Fatalf("AttachPrices: site %s: no price trace for region %q", s.ID, s.Tariff)
+		}
+	}
+}
+
+// LoadRenewableTracesFromCSV reads on-site generation traces (e.g. a
+// PV or wind output export) by region, with the same time and region
+// columns as LoadCITracesFromCSV. Values are returned in kW; columns whose
+// header mentions MW are converted.
+func LoadRenewableTracesFromCSV(path string) map[string]*core.Trace {
+	traces, col := loadTraceColumn(path, []string{
+		"generation_kw",
+		"power_kw",
+		"generation_mw",
+		"power_mw",
+		"solar",
+		"wind",
+		"generation",
+		"power",
+		"value",
+	}, false)
+	if strings.Contains(col, "mw") {
+		for _, tr := range traces {
+			for i := range tr.Values {
+				tr.Values[i] *= 1000
+			}
+		}
+	}
+	return traces
+}
+
+// AttachRenewables gives every site with a RenewableRegion its generation
+// trace. With RenewableKW set the trace is rescaled so its peak is the
+// installed capacity, so capacity factors and a farm's output in MW can
+// both stand in for a smaller on-site array.
+func AttachRenewables(sites map[string]*core.Site, traces map[string]*core.Trace) {
+	for _, s := range sites {
+		if s.RenewableRegion == "" {
+			continue
+		}
+		tr, ok := traces[s.RenewableRegion]
+		if !ok {
+			log.Fatalf("AttachRenewables: site %s: no renewable trace for region %q", s.ID, s.RenewableRegion)
+		}
+		if s.RenewableKW > 0 {
+			peak := 0.0
+			for _, v := range tr.Values {
+				peak = math.Max(peak, v)
+			}
+			if peak > 0 {
+				scaled := *tr
+				scaled.Values = make([]float64, len(tr.Values))
+				for i, v := range tr.Values {
+					scaled.Values[i] = v * s.RenewableKW / peak
+				}
+				tr = &scaled
+			}
+		}
+		s.Renewable = tr
+	}
+}
+
+// LoadPUETracesFromCSV reads measured PUE traces (e.g. hourly) by region,
+// with the same time and region columns as LoadCITracesFromCSV.
+func LoadPUETracesFromCSV(path string) map[string]*core.Trace {
+	traces, _ := loadTraceColumn(path, []string{"pue", "value"}, false)
+	return traces
+}
+
+// LoadTempTracesFromCSV reads outside-temperature traces (e.g. a weather
+// service export) by region, with the same time and region columns as
+// LoadCITracesFromCSV. Values are returned in °C; Kelvin columns (ERA5's
+// "t2m", or a header ending in "_k") are converted.
+func LoadTempTracesFromCSV(path string) map[string]*core.Trace {
+	traces, col := loadTraceColumn(path, []string{
+		"temperature_c",
+		"temp_c",
+		"temperature_k",
+		"temp_k",
+		"t2m",
+		"temperature",
+		"temp",
+		"value",
+	}, false)
+	if col == "t2m" || strings.HasSuffix(col, "_k") {
+		for _, tr := range traces {
+			for i := range tr.Values {
+				tr.Values[i] -= 273.15
+			}
+		}
+	}
+	return traces
+}
+
+// AttachPUE gives every site with a PUEModel its PUE series:
+//
+//	trace:<region>                   a PUE trace of pueTraces
+//	temp:<region>:<°C>=<pue>:…       a temperature trace of tempTraces
+//	                                 through a PUE curve (core.PUECurve)
+func AttachPUE(sites map[string]*core.Site, pueTraces, tempTraces map[string]*core.Trace) {
+	for _, s := range sites {
+		if s.PUEModel == "" {
+			continue
+		}
+		kind, rest, _ := strings.Cut(s.PUEModel, ":")
+		switch kind {
+		case "trace":
+			tr, ok := pueTraces[rest]
+			if !ok {
+				log.Fatalf("AttachPUE: site %s: no PUE trace for region %q", s.ID, rest)
+			}
+			s.PUESeries = tr
+		case "temp":
+			region, spec, _ := strings.Cut(rest, ":")
+			tr, ok := tempTraces[region]
+			if !ok {
+				log.Fatalf("AttachPUE: site %s: no temperature trace for region %q", s.ID, region)
+			}
+			c, err := core.ParsePUECurve(spec)
+			if err != nil {
+				log.Fatalf("AttachPUE: site %s: %v", s.ID, err)
+			}
+			s.PUESeries = core.TempPUE{Temp: tr, Curve: c}
+		default:
+			log.Fatalf("AttachPUE: site %s: unknown pue_model %q (want trace:<region> or temp:<region>:<°C>=<pue>:…)", s.ID, s.PUEModel)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"kube-scheduler/pkg/core"
+)
+
+// defaultAccountStep bounds the interval over which CI is taken as constant
+// when integrating emissions.
+const defaultAccountStep = 5 * time.Minute
+
+// JobEnergy is the dynamic energy and emissions attributed to one job.
+type JobEnergy struct {
+	KWh         float64 // IT energy above idle
+	FacilityKWh float64 // KWh × the site's PUE × k at the time
+	CO2g        float64 // including the site's PUE × k, in the run's emissions mode
+	EmbodiedG   float64 // embodied gCO₂e amortised over the reserved share
+	EUR         float64 // electricity cost of the facility energy
+
+	AverageCO2g, MarginalCO2g float64 // CO2g under each accounting, whatever the mode
+}
+
+// NodeEnergy is one node's energy over the accounting window.
+type NodeEnergy struct {
+	SiteID      string
+	IdleKWh     float64 // static draw, charged whether or not jobs run
+	DynamicKWh  float64 // draw above idle, from its reservations
+	FacilityKWh float64 // idle + dynamic × the site's PUE × k at the time
+	IdleCO2g    float64
+	DynamicCO2g float64
+	IdleEUR     float64
+	DynamicEUR  float64
+}
+
+// Accounting integrates node power over simulated time. Unlike
+// ComputeCICost, which charges every job idle power for its own duration,
+// idle power is charged once per node for the whole window and dynamic power
+// is attributed to the jobs that cause it.
+type Accounting struct {
+	From, To time.Time
+	Jobs     map[string]JobEnergy  // by job ID
+	Nodes    map[string]NodeEnergy // by node name
+
+	IdleKWh, DynamicKWh   float64 // IT energy
+	FacilityKWh           float64 // IT energy × PUE × k, with PUE at the time it was drawn
+	IdleCO2g, DynamicCO2g float64 // facility emissions of the grid energy (PUE × k applied)
+
+	// Total emissions under average and marginal accounting, whatever the
+	// run's emissions mode (equal where no site has a marginal trace).
+	AverageCO2g, MarginalCO2g float64
+	EmbodiedG                 float64 // embodied emissions of the reserved node shares
+	IdleEUR, DynamicEUR       float64 // grid electricity cost at the site tariffs (PUE × k applied)
+
+	GridKWh float64               // facility energy drawn from the grid
+	OnSite  map[string]SiteSupply // by site ID, for sites with renewables or a battery
+}
+
+func (a Accounting) TotalKWh() float64  { return a.IdleKWh + a.DynamicKWh }
+func (a Accounting) TotalCO2g() float64 { return a.IdleCO2g + a.DynamicCO2g }
+func (a Accounting) TotalEUR() float64  { return a.IdleEUR + a.DynamicEUR }
+
+// RenewableKWh is the on-site generation used directly by all sites.
+func (a Accounting) RenewableKWh() float64 {
+	sum := 0.0
+	for _, s := range a.OnSite {
+		sum += s.RenewableKWh
+	}
+	return sum
+}
+
+// BatteryKWh is the energy all site batteries delivered to their loads.
+func (a Accounting) BatteryKWh() float64 {
+	sum := 0.0
+	for _, s := range a.OnSite {
+		sum += s.BatteryKWh
+	}
+	return sum
+}
+
+// SCIPerJob is the run's Software Carbon Intensity with the job as the
+// functional unit: all operational emissions (idle included) plus the
+// embodied share, per job.
+func (a Accounting) SCIPerJob() float64 {
+	return (a.TotalCO2g() + a.EmbodiedG) / float64(len(a.Jobs))
+}
+
+// Account runs the energy accountant over logs produced on nodes. The window
+// is the span of the logs (first submission to last completion), with CI
+// and electricity prices sampled at least every step (0 = 5m). Each node's
+// draw follows its PowerModel at the utilisation of all jobs running on it;
+// the draw above idle is split between those jobs by CPU share, so
+// non-linear curves are charged at the load the node actually ran at.
+// Sites with a time-varying PUE apply it at the time energy was drawn.
+//
+// Sites with on-site renewables or a battery meet their facility load from
+// generation first, then the battery, then the grid (see dispatch); only
+// the grid share of each step is charged CI and price.
+func Account(logs []core.LogEntry, nodes []*core.SimulatedNode, step time.Duration) Accounting {
+	if step <= 0 {
+		step = defaultAccountStep
+	}
+	from, to := Span(logs)
+	a := Accounting{
+		From:  from,
+		To:    to,
+		Jobs:  make(map[string]JobEnergy, len(logs)),
+		Nodes: make(map[string]NodeEnergy, len(nodes)),
+	}
+	onNode := map[string][]core.LogEntry{}
+	for _, e := range logs {
+		onNode[e.Node] = append(onNode[e.Node], e)
+	}
+	grid := a.dispatchSites(nodes, onNode, step)
+
+	hours := to.Sub(from).Hours()
+	for _, n := range nodes {
+		idleW := PowerW(n, 0)
+		g := grid[n.Site]
+		ne := NodeEnergy{SiteID: n.SiteID, IdleKWh: idleW * hours / 1000.0}
+		ne.FacilityKWh = ne.IdleKWh * facilityMean(n, one, from, to, step)
+		mode, avg, marg := gridCO2(n, g, from, to, step)
+		ne.IdleCO2g = ne.IdleKWh * mode
+		a.AverageCO2g += ne.IdleKWh * avg
+		a.MarginalCO2g += ne.IdleKWh * marg
+		ne.IdleEUR = ne.IdleKWh * meanPrice(n, g, from, to, step)
+		a.IdleKWh += ne.IdleKWh
+		a.IdleCO2g += ne.IdleCO2g
+		a.IdleEUR += ne.IdleEUR
+
+		for id, je := range dynamicEnergy(n, onNode[n.Name], g, step) {
+			prev := a.Jobs[id] // a job may run in several segments
+			prev.KWh += je.KWh
+			prev.FacilityKWh += je.FacilityKWh
+			prev.CO2g += je.CO2g
+			prev.AverageCO2g += je.AverageCO2g
+			prev.MarginalCO2g += je.MarginalCO2g
+			prev.EUR += je.EUR
+			a.Jobs[id] = prev
+			ne.DynamicKWh += je.KWh
+			ne.FacilityKWh += je.FacilityKWh
+			ne.DynamicCO2g += je.CO2g
+			a.AverageCO2g += je.AverageCO2g
+			a.MarginalCO2g += je.MarginalCO2g
+			ne.DynamicEUR += je.EUR
+		}
+		for _, e := range onNode[n.Name] {
+			m := n.EmbodiedG(e.CPU, e.Runtime())
+			je := a.Jobs[e.JobID]
+			je.EmbodiedG += m
+			a.Jobs[e.JobID] = je
+			a.EmbodiedG += m
+		}
+		a.Nodes[n.Name] = ne
+		a.DynamicKWh += ne.DynamicKWh
+		a.DynamicCO2g += ne.DynamicCO2g
+		a.DynamicEUR += ne.DynamicEUR
+		a.FacilityKWh += ne.FacilityKWh
+		if g == nil {
+			a.GridKWh += ne.FacilityKWh
+		}
+	}
+	for _, s := range a.OnSite {
+		a.GridKWh += s.GridKWh
+	}
+	return a
+}
+
+// dynamicEnergy attributes n's draw above idle to the runs on it. While
+// the set of running jobs is fixed the node draws PowerW(n, util) -
+// PowerW(n, 0) above idle, shared by CPU request; g is the grid share of
+// the site's load (nil = all grid).
+func dynamicEnergy(n *core.SimulatedNode, runs []core.LogEntry, g *gridShare, step time.Duration) map[string]JobEnergy {
+	out := make(map[string]JobEnergy, len(runs))
+	idleW := PowerW(n, 0)
+	sweepRuns(n, runs, func(t0, t1 time.Time, util, cpu float64, active map[int]bool) {
+		kwh := (PowerW(n, util) - idleW) * t1.Sub(t0).Hours() / 1000.0
+		sf := facilityMean(n, one, t0, t1, step)
+		gPerKWh, avg, marg := gridCO2(n, g, t0, t1, step)
+		eurPerKWh := meanPrice(n, g, t0, t1, step)
+		for i := range active {
+			share := runs[i].CPU / cpu
+			je := out[runs[i].JobID]
+			je.KWh += kwh * share
+			je.FacilityKWh += kwh * share * sf
+			je.CO2g += kwh * share * gPerKWh
+			je.AverageCO2g += kwh * share * avg
+			je.MarginalCO2g += kwh * share * marg
+			je.EUR += kwh * share * eurPerKWh
+			out[runs[i].JobID] = je
+		}
+	})
+	return out
+}
+
+// sweepRuns visits the runs on n in time order, calling f for every
+// interval between consecutive starts and ends with a non-empty, fixed set
+// of running jobs (indices into runs), their CPU and the node utilisation.
+func sweepRuns(n *core.SimulatedNode, runs []core.LogEntry, f func(t0, t1 time.Time, util, cpu float64, active map[int]bool)) {
+	type edge struct {
+		at    time.Time
+		run   int
+		start bool
+	}
+	edges := make([]edge, 0, 2*len(runs))
+	for i, e := range runs {
+		if e.End.After(e.Start) {
+			edges = append(edges, edge{e.Start, i, true}, edge{e.End, i, false})
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if !edges[i].at.Equal(edges[j].at) {
+			return edges[i].at.Before(edges[j].at)
+		}
+		return !edges[i].start && edges[j].start // ends first
+	})
+
+	active := map[int]bool{}
+	cpu := 0.0
+	for k, ed := range edges {
+		if ed.start {
+			active[ed.run] = true
+			cpu += runs[ed.run].CPU
+		} else {
+			delete(active, ed.run)
+			cpu -= runs[ed.run].CPU
+		}
+		if k+1 == len(edges) || len(active) == 0 || cpu <= 0 {
+			continue
+		}
+		t0, t1 := ed.at, edges[k+1].at
+		if !t1.After(t0) {
+			continue
+		}
+		util := 1.0
+		if n.TotalCPU > 0 {
+			util = math.Min(cpu/n.TotalCPU, 1)
+		}
+		f(t0, t1, util, cpu, active)
+	}
+}
+
+// gridCO2 is the emissions per IT kWh of n over [a, b) in the run's
+// emissions mode, and under average and marginal accounting.
+func gridCO2(n *core.SimulatedNode, g *gridShare, a, b time.Time, step time.Duration) (mode, avg, marg float64) {
+	avg = meanCI(n, averageCI, g, a, b, step)
+	marg = avg
+	if n.Site != nil && n.Site.MarginalCI != nil {
+		marg = meanCI(n, marginalCI, g, a, b, step)
+	}
+	if n.Site != nil && n.Site.Marginal {
+		return marg, avg, marg
+	}
+	return avg, avg, marg
+}
+
+// meanCI is the time-weighted mean emissions per IT kWh of n over [a, b):
+// ci × PUE × k, scaled by the grid share g (nil = all grid).
+func meanCI(n *core.SimulatedNode, ci func(*core.SimulatedNode, time.Time) float64, g *gridShare, a, b time.Time, step time.Duration) float64 {
+	if g == nil {
+		return facilityMean(n, func(t time.Time) float64 { return ci(n, t) }, a, b, step)
+	}
+	return facilityMean(n, func(t time.Time) float64 { return ci(n, t) * g.At(t) }, a, b, step)
+}
+
+// meanPrice is the time-weighted mean electricity cost per IT kWh of n over
+// [a, b): price × PUE × k, scaled by the grid share g (nil = all grid).
+func meanPrice(n *core.SimulatedNode, g *gridShare, a, b time.Time, step time.Duration) float64 {
+	if n.Site == nil || n.Site.Price == nil {
+		return 0
+	}
+	if g == nil {
+		return facilityMean(n, n.Site.Price.At, a, b, step)
+	}
+	return facilityMean(n, func(t time.Time) float64 { return n.Site.Price.At(t) * g.At(t) }, a, b, step)
+}
+
+func one(time.Time) float64 { return 1 }
+
+// facilityMean is the time-weighted mean of f × PUE × k of n's site over
+// [a, b); a constant PUE is factored out of the integral.
+func facilityMean(n *core.SimulatedNode, f func(time.Time) float64, a, b time.Time, step time.Duration) float64 {
+	if n.Site == nil || n.Site.PUESeries == nil {
+		return meanOver(f, a, b, step) * SiteFactor(n)
+	}
+	return meanOver(func(t time.Time) float64 { return f(t) * SiteFactorAt(n, t) }, a, b, step)
+}
+
+// meanOver is the time-weighted mean of f over [a, b), sampling the
+// midpoint of sub-intervals no longer than step.
+func meanOver(f func(time.Time) float64, a, b time.Time, step time.Duration) float64 {
+	if !b.After(a) {
+		return f(a)
+	}
+	sum := 0.0
+	for t := a; t.Before(b); t = t.Add(step) {
+		end := t.Add(step)
+		if end.After(b) {
+			end = b
+		}
+		d := end.Sub(t)
+		sum += f(t.Add(d/2)) * d.Seconds()
+	}
+	return sum / b.Sub(a).Seconds()
+}
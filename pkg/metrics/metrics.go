@@ -8,45 +8,153 @@ import (
 	"kube-scheduler/pkg/core"
 )
 
-func parsePeakPower(s string, def float64) float64 {
-    v, err := strconv.ParseFloat(s, 64)
-    if err != nil || v <= 0 { return def }
-    return v
-}
-
 // computeCICost estimates the grams of CO₂ emitted by running workload w
 // on node n starting at time t. It uses:
 //  1) a time-varying CI profile (static, sine-wave, or random-walk)
 //  2) an energy model: node peak power × CPU share × duration
 //  3) unit conversions (W→kWh, then × gCO₂/kWh)
 func ComputeCICost(n *core.SimulatedNode, w core.Workload, t time.Time) float64 {
-	ci := currentCI(n, t) // gCO2/kWh
-	pPeak := parsePeakPower(n.Metadata["peak_power_w"], 400.0) // Default is 400 watts.
+	return CICostWithCI(n, w, t, currentCI(n, t))
+}
+
+// CICostWithCI is ComputeCICost with the carbon intensity supplied by the
+// caller (e.g. a forecast of the mean CI over the job's window starting t).
+func CICostWithCI(n *core.SimulatedNode, w core.Workload, t time.Time, ci float64) float64 {
+	return EnergyKWh(n, w) * ci * SiteFactorOver(n, t, w.Duration)
+}
 
+// EnergyKWh is the energy attributed to w on n: the node's draw at w's CPU
+// share, over w's duration, in kWh. (Before the metrics rework it returned
+// Wh, inflating every CI cost built on it by 1000; weights that compare the
+// cost against non-carbon terms in absolute units were rescaled with it.)
+func EnergyKWh(n *core.SimulatedNode, w core.Workload) float64 {
 	cpuFrac := 0.0
 	if n.TotalCPU > 0 {
 		cpuFrac = w.CPU / n.TotalCPU
 	}
+	return PowerW(n, cpuFrac) * math.Max(w.Duration.Seconds(), 0) / 3600.0 / 1000.0 // W·s → kWh
+}
+
+// EmbodiedG is the share of n's embodied emissions (gCO₂e) amortised onto
+// w: its CPU share of the node for its duration, over the node lifetime.
+func EmbodiedG(n *core.SimulatedNode, w core.Workload) float64 {
+	return n.EmbodiedG(w.CPU, w.Duration)
+}
+
+// SCI is the Green Software Foundation Software Carbon Intensity of w on n
+// per job starting t, (E × I) + M, with I the supplied CI and PUE × k
+// applied to E.
+func SCI(n *core.SimulatedNode, w core.Workload, t time.Time, ci float64) float64 {
+	return CICostWithCI(n, w, t, ci) + EmbodiedG(n, w)
+}
 
-	idleFrac := 0.15
-	powerW := pPeak*idleFrac + cpuFrac*math.Max(pPeak - pPeak*idleFrac, 0)
-	energyKWh := powerW * math.Max(w.Duration.Seconds(), 0) / 3600.0
+// PowerW is the node's draw (W) at the given CPU utilisation (0..1), from
+// its PowerModel (15% idle, linear to peak_power_w when none is set).
+func PowerW(n *core.SimulatedNode, cpuFrac float64) float64 {
+	return n.PowerModel().Power(cpuFrac)
+}
 
+// SiteFactor is PUE × k of the node's site at its nominal PUE (1 without a
+// site).
+func SiteFactor(n *core.SimulatedNode) float64 {
 	pue := 1.0
 	k := 1.0
 	if n.Site != nil {
 		if n.Site.PUE > 0 { pue = n.Site.PUE }
 		if n.Site.K > 0 { k = n.Site.K }
 	}
-	return energyKWh * ci * pue * k
+	return pue * k
+}
+
+// SiteFactorAt is PUE × k of the node's site at t, following a
+// time-varying PUE when the site has one.
+func SiteFactorAt(n *core.SimulatedNode, t time.Time) float64 {
+	if n.Site == nil || n.Site.PUESeries == nil {
+		return SiteFactor(n)
+	}
+	return n.Site.PUEAt(t) * siteK(n)
+}
+
+// SiteFactorOver is SiteFactorAt averaged over [t, t+d).
+func SiteFactorOver(n *core.SimulatedNode, t time.Time, d time.Duration) float64 {
+	if n.Site == nil || n.Site.PUESeries == nil {
+		return SiteFactor(n)
+	}
+	return n.Site.PUEOver(t, d) * siteK(n)
+}
+
+func siteK(n *core.SimulatedNode) float64 {
+	if n.Site != nil && n.Site.K > 0 {
+		return n.Site.K
+	}
+	return 1
 }
 
-// currentCI parses the node’s ci_profile metadata and returns the
-// carbon intensity at time t (gCO₂/kWh). Supports:
+// PriceAt returns the electricity price (€/kWh) at the node's site at t
+// (0 without a site tariff).
+func PriceAt(n *core.SimulatedNode, t time.Time) float64 {
+	if n.Site == nil || n.Site.Price == nil {
+		return 0
+	}
+	return n.Site.Price.At(t)
+}
+
+// CostEUR is the electricity cost of w on n starting at t: the facility
+// energy (EnergyKWh × PUE × k) at the site's mean price over w's window.
+func CostEUR(n *core.SimulatedNode, w core.Workload, t time.Time) float64 {
+	if n.Site == nil || n.Site.Price == nil {
+		return 0
+	}
+	return EnergyKWh(n, w) * SiteFactorOver(n, t, w.Duration) * core.SeriesMean(n.Site.Price, t, w.Duration, 5*time.Minute)
+}
+
+// RenewableKW returns the on-site generation (kW) at the node's site at t
+// (0 without renewables).
+func RenewableKW(n *core.SimulatedNode, t time.Time) float64 {
+	if n.Site == nil || n.Site.Renewable == nil {
+		return 0
+	}
+	return math.Max(0, n.Site.Renewable.At(t))
+}
+
+// CIAt returns the node's carbon intensity (gCO₂/kWh) at time t, marginal
+// or average as the run's emissions mode selects.
+func CIAt(n *core.SimulatedNode, t time.Time) float64 { return currentCI(n, t) }
+
+// currentCI returns the carbon intensity at time t (gCO₂/kWh) in the run's
+// emissions mode: the site's marginal trace when its Marginal is set,
+// otherwise averageCI.
+func currentCI(n *core.SimulatedNode, t time.Time) float64 {
+	if n.Site != nil && n.Site.Marginal && n.Site.MarginalCI != nil {
+		return n.Site.MarginalCI.At(t)
+	}
+	return averageCI(n, t)
+}
+
+// marginalCI is the site's marginal emissions at t, falling back to
+// averageCI where the region has no marginal trace.
+func marginalCI(n *core.SimulatedNode, t time.Time) float64 {
+	if n.Site != nil && n.Site.MarginalCI != nil {
+		return n.Site.MarginalCI.At(t)
+	}
+	return averageCI(n, t)
+}
+
+// averageCI returns the average grid intensity at time t (gCO₂/kWh). A grid
+// trace attached to the node's site (via Site.CIRegion) takes precedence,
+// then a stochastic process attached by loader.AttachCIProcesses;
+// otherwise the node’s ci_profile metadata is parsed. Supports:
 //   static:<value>
 //   sine:<mean>:<amp>:<periodSec>
-//   randwalk:<min>:<max>:<stepSec>  (uses n.CarbonIntensity as last value)
-func currentCI(n *core.SimulatedNode, t time.Time) float64 {
+//   randwalk:<min>:<max>:<stepSec>  (n.CarbonIntensity until a process is attached)
+//   ou:<mean>:<theta>:<sigma>:<stepSec>[:<min>:<max>]  (likewise)
+func averageCI(n *core.SimulatedNode, t time.Time) float64 {
+	if n.Site != nil && n.Site.CI != nil {
+		return n.Site.CI.At(t)
+	}
+	if n.CISeries != nil {
+		return n.CISeries.At(t)
+	}
 	prof := n.Metadata["ci_profile"]
 	parts := strings.Split(prof, ":")
 	switch parts[0] {
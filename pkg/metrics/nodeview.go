@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"math"
+	"time"
+
+	"kube-scheduler/pkg/core"
+)
+
+// NodeView materialises the core.Node a Scheduler scores for job j on n at
+// time now. On top of core.NodeView's cpu_used/mem_used it fills:
+//
+//	ci_g_per_kwh         live CI (site trace, CI process or ci_profile)
+//	ci_norm              the CI clamped to 0..1 as (ci-50)/650
+//	node_power_w         node draw at its current utilisation
+//	job_energy_kwh_pred  energy attributed to j on n (as in ComputeCICost)
+//	job_duration_s_pred  j's estimated duration
+//	embodied_g_pred      embodied gCO₂e amortised onto j (see EmbodiedG)
+//	sci_pred             predicted SCI of j on n: energy × CI × PUE × k + embodied
+//	pue                  the site PUE now (1 without a site)
+//	price_eur_per_kwh    the site's electricity price now (0 without a tariff)
+//	job_cost_eur_pred    predicted electricity cost of j on n (see CostEUR)
+//	renewable_kw         the site's on-site generation now (0 without renewables)
+func NodeView(n *core.SimulatedNode, j core.Job, now time.Time) core.Node {
+	v := core.NodeView(n)
+	w := core.Workload{CPU: j.CPUReq, Memory: j.MemReq, Duration: time.Duration(j.EstimatedDuration * float64(time.Second))}
+
+	ci := currentCI(n, now)
+	util := 0.0
+	if n.TotalCPU > 0 {
+		util = (n.TotalCPU - n.AvailableCPU) / n.TotalCPU
+	}
+	pue := 1.0
+	if n.Site != nil {
+		pue = n.Site.PUEAt(now)
+	}
+	eKWh := EnergyKWh(n, w)
+
+	v.Metrics["ci_g_per_kwh"] = ci
+	v.Metrics["ci_norm"] = math.Max(0, math.Min(1, (ci-50.0)/650.0))
+	v.Metrics["node_power_w"] = PowerW(n, util)
+	v.Metrics["job_energy_kwh_pred"] = eKWh
+	v.Metrics["job_duration_s_pred"] = j.EstimatedDuration
+	v.Metrics["embodied_g_pred"] = EmbodiedG(n, w)
+	v.Metrics["sci_pred"] = SCI(n, w, now, ci)
+	v.Metrics["pue"] = pue
+	v.Metrics["price_eur_per_kwh"] = PriceAt(n, now)
+	v.Metrics["job_cost_eur_pred"] = CostEUR(n, w, now)
+	v.Metrics["renewable_kw"] = RenewableKW(n, now)
+	return v
+}
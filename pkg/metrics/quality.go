@@ -0,0 +1,444 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"kube-scheduler/pkg/core"
+)
+
+// DefaultSlowdownTau is the runtime floor of the bounded slowdown, so very
+// short jobs do not dominate it.
+const DefaultSlowdownTau = 10 * time.Second
+
+// Span returns the first submission and the last completion of logs.
+func Span(logs []core.LogEntry) (first, last time.Time) {
+	for i, e := range logs {
+		if i == 0 || e.Submit.Before(first) {
+			first = e.Submit
+		}
+		if i == 0 || e.End.After(last) {
+			last = e.End
+		}
+	}
+	return first, last
+}
+
+// MergeSegments folds the segments of preempted jobs into one entry per
+// job, in order of first start: Start, WaitMS and the request are the first
+// segment's, End, Node and SiteID the last one's, and CICost, EnergyKWh,
+// Restore, LostWork and WastedKWh are summed. The merged Runtime spans the
+// suspensions; Executed is the run time of the segments less their restores
+// and lost work. Logs without resumed segments are returned as is.
+func MergeSegments(logs []core.LogEntry) []core.LogEntry {
+	resumed := false
+	for _, e := range logs {
+		if e.Segment > 0 {
+			resumed = true
+			break
+		}
+	}
+	if !resumed {
+		return logs
+	}
+	out := make([]core.LogEntry, 0, len(logs))
+	at := map[string]int{}
+	for _, e := range logs {
+		run := e.Runtime() - e.Restore - e.LostWork
+		i, ok := at[e.JobID]
+		if !ok {
+			at[e.JobID] = len(out)
+			e.Executed = run
+			out = append(out, e)
+			continue
+		}
+		m := &out[i]
+		m.Executed += run
+		if e.End.After(m.End) {
+			m.End, m.Node, m.SiteID = e.End, e.Node, e.SiteID
+			m.Preempted = e.Preempted
+		}
+		if e.Preemptions > m.Preemptions {
+			m.Preemptions = e.Preemptions
+		}
+		m.CICost += e.CICost
+		m.EnergyKWh += e.EnergyKWh
+		m.Restore += e.Restore
+		m.LostWork += e.LostWork
+		m.WastedKWh += e.WastedKWh
+	}
+	return out
+}
+
+// Makespan is the time from the first submission to the last completion.
+func Makespan(logs []core.LogEntry) time.Duration {
+	first, last := Span(logs)
+	return last.Sub(first)
+}
+
+// Utilisation is the time-averaged share of CPU capacity in use over the
+// makespan, per node and for the whole cluster. caps maps node name to its
+// CPU capacity; nodes that ran nothing report 0.
+func Utilisation(logs []core.LogEntry, caps map[string]float64) (cluster float64, perNode map[string]float64) {
+	span := Makespan(logs).Seconds()
+	perNode = make(map[string]float64, len(caps))
+	for name := range caps {
+		perNode[name] = 0
+	}
+	if span <= 0 {
+		return 0, perNode
+	}
+	busy := map[string]float64{}
+	for _, e := range logs {
+		busy[e.Node] += e.CPU * e.Runtime().Seconds()
+	}
+	total, capSum := 0.0, 0.0
+	for name, c := range caps {
+		capSum += c
+		total += busy[name]
+		if c > 0 {
+			perNode[name] = busy[name] / (c * span)
+		}
+	}
+	if capSum == 0 {
+		return 0, perNode
+	}
+	return total / (capSum * span), perNode
+}
+
+// UtilSample is the CPU utilisation averaged over [Time, Time+step).
+type UtilSample struct {
+	Time    time.Time
+	Cluster float64
+	Nodes   map[string]float64
+}
+
+// UtilisationSeries buckets CPU utilisation over the makespan into steps.
+func UtilisationSeries(logs []core.LogEntry, caps map[string]float64, step time.Duration) []UtilSample {
+	first, last := Span(logs)
+	if step <= 0 || !last.After(first) {
+		return nil
+	}
+	n := int((last.Sub(first) + step - 1) / step)
+	busy := make([]map[string]float64, n) // core-seconds per node per bucket
+	for i := range busy {
+		busy[i] = map[string]float64{}
+	}
+	for _, e := range logs {
+		for k := int(e.Start.Sub(first) / step); k < n; k++ {
+			b0 := first.Add(time.Duration(k) * step)
+			b1 := b0.Add(step)
+			if !e.End.After(b0) {
+				break
+			}
+			lo, hi := e.Start, e.End
+			if lo.Before(b0) {
+				lo = b0
+			}
+			if hi.After(b1) {
+				hi = b1
+			}
+			busy[k][e.Node] += e.CPU * hi.Sub(lo).Seconds()
+		}
+	}
+	capSum := 0.0
+	for _, c := range caps {
+		capSum += c
+	}
+	out := make([]UtilSample, n)
+	for k := range out {
+		s := UtilSample{Time: first.Add(time.Duration(k) * step), Nodes: make(map[string]float64, len(caps))}
+		total := 0.0
+		for name, c := range caps {
+			total += busy[k][name]
+			if c > 0 {
+				s.Nodes[name] = busy[k][name] / (c * step.Seconds())
+			}
+		}
+		if capSum > 0 {
+			s.Cluster = total / (capSum * step.Seconds())
+		}
+		out[k] = s
+	}
+	return out
+}
+
+// BoundedSlowdown is max(1, (wait+runtime) / max(work, tau)), with work the
+// executed run time (LogEntry.Work).
+func BoundedSlowdown(e core.LogEntry, tau time.Duration) float64 {
+	run := e.Work()
+	if run < tau {
+		run = tau
+	}
+	if run <= 0 {
+		return 1
+	}
+	return math.Max(1, (e.Wait()+e.Runtime()).Seconds()/run.Seconds())
+}
+
+// MeanBoundedSlowdown averages BoundedSlowdown over logs.
+func MeanBoundedSlowdown(logs []core.LogEntry, tau time.Duration) float64 {
+	if len(logs) == 0 {
+		return math.NaN()
+	}
+	sum := 0.0
+	for _, e := range logs {
+		sum += BoundedSlowdown(e, tau)
+	}
+	return sum / float64(len(logs))
+}
+
+// WaitPercentile returns the q-quantile (0..1) of the waits in seconds,
+// linearly interpolated between order statistics.
+func WaitPercentile(logs []core.LogEntry, q float64) float64 {
+	if len(logs) == 0 {
+		return math.NaN()
+	}
+	w := make([]float64, len(logs))
+	for i, e := range logs {
+		w[i] = e.Wait().Seconds()
+	}
+	sort.Float64s(w)
+	pos := q * float64(len(w)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	return w[lo] + (pos-float64(lo))*(w[hi]-w[lo])
+}
+
+// DeadlineMissRate is the share of jobs with a deadline that missed it
+// (0 when no job had one).
+func DeadlineMissRate(logs []core.LogEntry) float64 {
+	with, missed := 0, 0
+	for _, e := range logs {
+		if e.Deadline.IsZero() {
+			continue
+		}
+		with++
+		if e.MissedDeadline() {
+			missed++
+		}
+	}
+	if with == 0 {
+		return 0
+	}
+	return float64(missed) / float64(with)
+}
+
+// RuntimePredictionError is the mean absolute percentage error of the
+// runtimes policies were told (LogEntry.PredRuntime) against the executed
+// ones (LogEntry.Work); NaN when no job carries a prediction.
+func RuntimePredictionError(logs []core.LogEntry) float64 {
+	sum, n := 0.0, 0
+	for _, e := range logs {
+		run := e.Work()
+		if e.PredRuntime <= 0 || run <= 0 {
+			continue
+		}
+		sum += math.Abs(e.PredRuntime.Seconds()-run.Seconds()) / run.Seconds()
+		n++
+	}
+	if n == 0 {
+		return math.NaN()
+	}
+	return sum / float64(n)
+}
+
+// Jain is Jain's fairness index (Σx)²/(n·Σx²): 1 when all values are equal,
+// 1/n when one value takes everything.
+func Jain(xs []float64) float64 {
+	if len(xs) == 0 {
+		return math.NaN()
+	}
+	sum, sq := 0.0, 0.0
+	for _, x := range xs {
+		sum += x
+		sq += x * x
+	}
+	if sq == 0 {
+		return 1
+	}
+	return sum * sum / (float64(len(xs)) * sq)
+}
+
+// JainByTag is Jain's index over the per-tag mean bounded slowdowns: how
+// evenly the scheduler treats the workload classes.
+func JainByTag(logs []core.LogEntry, tau time.Duration) float64 {
+	return jainBy(logs, tau, func(e core.LogEntry) string { return e.Tag })
+}
+
+// JainByTenant is JainByTag over the fair-share tenants.
+func JainByTenant(logs []core.LogEntry, tau time.Duration) float64 {
+	return jainBy(logs, tau, func(e core.LogEntry) string { return e.Tenant })
+}
+
+func jainBy(logs []core.LogEntry, tau time.Duration, key func(core.LogEntry) string) float64 {
+	sum := map[string]float64{}
+	cnt := map[string]int{}
+	for _, e := range logs {
+		sum[key(e)] += BoundedSlowdown(e, tau)
+		cnt[key(e)]++
+	}
+	xs := make([]float64, 0, len(sum))
+	for k, s := range sum {
+		xs = append(xs, s/float64(cnt[k]))
+	}
+	return Jain(xs)
+}
+
+// MaxTenantWait is the largest per-tenant mean wait (s).
+func MaxTenantWait(logs []core.LogEntry) float64 {
+	sum := map[string]float64{}
+	cnt := map[string]int{}
+	for _, e := range logs {
+		sum[e.Tenant] += e.Wait().Seconds()
+		cnt[e.Tenant]++
+	}
+	worst := math.NaN()
+	for t, s := range sum {
+		if m := s / float64(cnt[t]); math.IsNaN(worst) || m > worst {
+			worst = m
+		}
+	}
+	return worst
+}
+
+// ShareDeviation measures how closely the tenants' CPU use follows their
+// fair-share targets (tenant -> share, default 1) while they compete: at
+// every instant some job waits and two or more tenants have work (running
+// or waiting), it takes half the sum of the absolute differences between
+// each such tenant's share of the cores in use and its target share among
+// them, and averages that over the contended time. 0 means usage tracks the
+// shares; NaN if tenants never compete. logs are the run segments, so time
+// between the segments of a preempted job counts as waiting.
+func ShareDeviation(logs []core.LogEntry, shares map[string]float64) float64 {
+	type change struct {
+		t      time.Time
+		tenant string
+		cores  float64 // running cores
+		wait   int     // waiting jobs
+	}
+	var cs []change
+	byJob := map[string][]core.LogEntry{}
+	for _, e := range logs {
+		cs = append(cs, change{e.Start, e.Tenant, e.CPU, 0}, change{e.End, e.Tenant, -e.CPU, 0})
+		byJob[e.JobID] = append(byJob[e.JobID], e)
+	}
+	for _, segs := range byJob {
+		sort.Slice(segs, func(i, j int) bool { return segs[i].Start.Before(segs[j].Start) })
+		from := segs[0].Submit
+		for _, e := range segs {
+			if e.Start.After(from) {
+				cs = append(cs, change{from, e.Tenant, 0, 1}, change{e.Start, e.Tenant, 0, -1})
+			}
+			from = e.End
+		}
+	}
+	sort.SliceStable(cs, func(i, j int) bool { return cs[i].t.Before(cs[j].t) })
+
+	cores, waiting := map[string]float64{}, map[string]int{}
+	var tenants []string
+	sum, span := 0.0, 0.0
+	for i, c := range cs {
+		if _, ok := cores[c.tenant]; !ok {
+			tenants = append(tenants, c.tenant)
+			sort.Strings(tenants)
+		}
+		cores[c.tenant] += c.cores
+		waiting[c.tenant] += c.wait
+		if i+1 == len(cs) || !cs[i+1].t.After(c.t) {
+			continue
+		}
+		dt := cs[i+1].t.Sub(c.t).Seconds()
+		used, target, active, queued := 0.0, 0.0, 0, false
+		for _, t := range tenants {
+			if cores[t] > 1e-9 || waiting[t] > 0 {
+				used += math.Max(cores[t], 0)
+				target += shareOf(shares, t)
+				active++
+				queued = queued || waiting[t] > 0
+			}
+		}
+		if active < 2 || !queued || used <= 0 {
+			continue
+		}
+		dev := 0.0
+		for _, t := range tenants {
+			if cores[t] > 1e-9 || waiting[t] > 0 {
+				dev += math.Abs(math.Max(cores[t], 0)/used - shareOf(shares, t)/target)
+			}
+		}
+		sum += dev / 2 * dt
+		span += dt
+	}
+	if span == 0 {
+		return math.NaN()
+	}
+	return sum / span
+}
+
+func shareOf(shares map[string]float64, tenant string) float64 {
+	if s, ok := shares[tenant]; ok && s > 0 {
+		return s
+	}
+	return core.DefaultTenantShare
+}
+
+// TotalCO2 sums the jobs' CI cost (gCO₂).
+func TotalCO2(logs []core.LogEntry) float64 {
+	sum := 0.0
+	for _, e := range logs {
+		sum += e.CICost
+	}
+	return sum
+}
+
+// CPUHours sums requested cores × runtime.
+func CPUHours(logs []core.LogEntry) float64 {
+	sum := 0.0
+	for _, e := range logs {
+		sum += e.CPU * e.Runtime().Hours()
+	}
+	return sum
+}
+
+// SiteBreakdown aggregates one site of a run: its jobs from the logs, its
+// energy and emissions from the accountant.
+type SiteBreakdown struct {
+	Jobs      int
+	EnergyKWh float64 // IT energy of the site's nodes, idle included
+	CO2g      float64 // emissions of their grid energy (PUE × k applied)
+	CPUHours  float64
+	AvgWaitS  float64
+}
+
+// BySite groups a run by site ID ("" for nodes without a site). Energy and
+// CO2g sum acct's node totals, so idle power is charged once per node and
+// on-site supply and time-varying PUE count as in the run totals. Resumed
+// segments of preempted jobs add to the CPU hours of the site they ran at
+// but not to Jobs or AvgWaitS, which count where jobs first started.
+func BySite(logs []core.LogEntry, acct Accounting) map[string]SiteBreakdown {
+	out := map[string]SiteBreakdown{}
+	for _, ne := range acct.Nodes {
+		b := out[ne.SiteID]
+		b.EnergyKWh += ne.IdleKWh + ne.DynamicKWh
+		b.CO2g += ne.IdleCO2g + ne.DynamicCO2g
+		out[ne.SiteID] = b
+	}
+	for _, e := range logs {
+		b := out[e.SiteID]
+		if e.Segment == 0 {
+			b.Jobs++
+			b.AvgWaitS += e.Wait().Seconds()
+		}
+		b.CPUHours += e.CPU * e.Runtime().Hours()
+		out[e.SiteID] = b
+	}
+	for id, b := range out {
+		if b.Jobs > 0 {
+			b.AvgWaitS /= float64(b.Jobs)
+		}
+		out[id] = b
+	}
+	return out
+}
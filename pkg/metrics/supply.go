@@ -0,0 +1,165 @@
+package metrics
+
+import (
+	"math"
+	"time"
+
+	"kube-scheduler/pkg/core"
+)
+
+// SiteSupply is how a site's facility energy was met over the accounting
+// window, in the Ecovisor model of renewables first, then the battery, then
+// the grid.
+type SiteSupply struct {
+	LoadKWh      float64 // facility energy (IT × PUE × k)
+	RenewableKWh float64 // generation used directly
+	BatteryKWh   float64 // discharged to the load
+	GridKWh      float64
+	CurtailedKWh float64 // surplus generation the battery could not take
+	FinalSoCKWh  float64 // battery charge at the end of the window
+}
+
+// SiteDrawKW is the facility draw (IT × PUE × k, kW) of each site's nodes at
+// their current utilisation at t, by site ID.
+func SiteDrawKW(nodes []core.SimulatedNode, t time.Time) map[string]float64 {
+	out := map[string]float64{}
+	for i := range nodes {
+		n := &nodes[i]
+		util := 0.0
+		if n.TotalCPU > 0 {
+			util = (n.TotalCPU - n.AvailableCPU) / n.TotalCPU
+		}
+		out[n.SiteID] += PowerW(n, util) / 1000 * SiteFactorAt(n, t)
+	}
+	return out
+}
+
+// gridShare is the fraction of a site's load drawn from the grid in each
+// accounting step from `from`.
+type gridShare struct {
+	from time.Time
+	step time.Duration
+	frac []float64
+}
+
+func (g *gridShare) At(t time.Time) float64 {
+	i := int(t.Sub(g.from) / g.step)
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(g.frac) {
+		i = len(g.frac) - 1
+	}
+	return g.frac[i]
+}
+
+// dispatchSites runs dispatch for every site with on-site supply, filling
+// a.OnSite, and returns the grid share of each such site.
+func (a *Accounting) dispatchSites(nodes []*core.SimulatedNode, onNode map[string][]core.LogEntry, step time.Duration) map[*core.Site]*gridShare {
+	bySite := map[*core.Site][]*core.SimulatedNode{}
+	for _, n := range nodes {
+		if s := n.Site; s != nil && (s.Renewable != nil || s.Battery != nil) {
+			bySite[s] = append(bySite[s], n)
+		}
+	}
+	if len(bySite) == 0 || !a.To.After(a.From) {
+		return nil
+	}
+	a.OnSite = make(map[string]SiteSupply, len(bySite))
+	out := make(map[*core.Site]*gridShare, len(bySite))
+	for s, ns := range bySite {
+		sup, g := dispatch(s, ns, onNode, a.From, a.To, step)
+		a.OnSite[s.ID] = sup
+		out[s] = g
+	}
+	return out
+}
+
+// dispatch builds the facility load of site s's nodes per step and meets
+// it from the renewable trace first; surplus charges the battery (losing
+// 1-Efficiency on the way in) and deficits discharge it, both within
+// RateKW, before the rest is drawn from the grid. The battery never
+// charges from the grid.
+func dispatch(s *core.Site, nodes []*core.SimulatedNode, onNode map[string][]core.LogEntry, from, to time.Time, step time.Duration) (SiteSupply, *gridShare) {
+	nb := int((to.Sub(from) + step - 1) / step)
+	load := make([]float64, nb)
+	for _, n := range nodes {
+		sf := func(t time.Time) float64 { return SiteFactorAt(n, t) }
+		idleW := PowerW(n, 0)
+		spread(load, from, step, from, to, idleW/1000, sf)
+		sweepRuns(n, onNode[n.Name], func(t0, t1 time.Time, util, _ float64, _ map[int]bool) {
+			spread(load, from, step, t0, t1, (PowerW(n, util)-idleW)/1000, sf)
+		})
+	}
+
+	var sup SiteSupply
+	var soc, eff float64
+	if b := s.Battery; b != nil {
+		soc = b.CapacityKWh * math.Max(0, math.Min(1, b.InitialSoC))
+		eff = b.Efficiency
+		if eff <= 0 || eff > 1 {
+			eff = 1
+		}
+	}
+	g := &gridShare{from: from, step: step, frac: make([]float64, nb)}
+	for i := range load {
+		b0 := from.Add(time.Duration(i) * step)
+		b1 := b0.Add(step)
+		if b1.After(to) {
+			b1 = to
+		}
+		h := b1.Sub(b0).Hours()
+		gen := 0.0
+		if s.Renewable != nil {
+			gen = math.Max(0, s.Renewable.At(b0.Add(b1.Sub(b0)/2))) * h
+		}
+		direct := math.Min(gen, load[i])
+		surplus, deficit := gen-direct, load[i]-direct
+		var in, out float64
+		if b := s.Battery; b != nil {
+			limit := math.Inf(1)
+			if b.RateKW > 0 {
+				limit = b.RateKW * h
+			}
+			in = math.Min(surplus, math.Min(limit, (b.CapacityKWh-soc)/eff))
+			soc += in * eff
+			out = math.Min(deficit, math.Min(limit, soc))
+			soc -= out
+		}
+		gridKWh := deficit - out
+
+		sup.LoadKWh += load[i]
+		sup.RenewableKWh += direct
+		sup.BatteryKWh += out
+		sup.GridKWh += gridKWh
+		sup.CurtailedKWh += surplus - in
+		g.frac[i] = 1
+		if load[i] > 0 {
+			g.frac[i] = gridKWh / load[i]
+		}
+	}
+	sup.FinalSoCKWh = soc
+	return sup, g
+}
+
+// spread adds a constant IT draw of kw over [a, b), scaled by the site
+// factor sf at the middle of each overlap, to the per-step energy buckets
+// starting at from.
+func spread(buckets []float64, from time.Time, step time.Duration, a, b time.Time, kw float64, sf func(time.Time) float64) {
+	for i := int(a.Sub(from) / step); i < len(buckets) && i >= 0; i++ {
+		b0 := from.Add(time.Duration(i) * step)
+		if !b0.Before(b) {
+			break
+		}
+		lo, hi := b0, b0.Add(step)
+		if a.After(lo) {
+			lo = a
+		}
+		if b.Before(hi) {
+			hi = b
+		}
+		if hi.After(lo) {
+			buckets[i] += kw * sf(lo.Add(hi.Sub(lo)/2)) * hi.Sub(lo).Hours()
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"kube-scheduler/pkg/core"
+)
+
+var t0 = time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+// hourly is on-site generation (kW), constant within each hour from t0.
+type hourly []float64
+
+func (g hourly) At(t time.Time) float64 {
+	if i := int(t.Sub(t0) / time.Hour); i >= 0 && i < len(g) {
+		return g[i]
+	}
+	return 0
+}
+
+func TestAccountSplitsGridRenewableBattery(t *testing.T) {
+	// One node drawing a constant 1 kW (IT) for 4h at a static 100 gCO₂/kWh,
+	// accounted hourly, so each hour's facility load is PUE kWh.
+	for _, tc := range []struct {
+		name    string
+		pue     float64
+		gen     hourly
+		battery *core.Battery
+		want    SiteSupply
+	}{
+		{name: "grid only", pue: 1,
+			want: SiteSupply{LoadKWh: 4, GridKWh: 4}},
+		{name: "renewables, surplus curtailed", pue: 1, gen: hourly{2, 0.5, 0, 0},
+			want: SiteSupply{LoadKWh: 4, RenewableKWh: 1.5, GridKWh: 2.5, CurtailedKWh: 1}},
+		{name: "surplus stored and discharged", pue: 1, gen: hourly{2, 0.5, 0, 0},
+			battery: &core.Battery{CapacityKWh: 2},
+			want:    SiteSupply{LoadKWh: 4, RenewableKWh: 1.5, BatteryKWh: 1, GridKWh: 1.5}},
+		{name: "round-trip loss on charge", pue: 1, gen: hourly{2, 0.5, 0, 0},
+			battery: &core.Battery{CapacityKWh: 2, Efficiency: 0.5},
+			want:    SiteSupply{LoadKWh: 4, RenewableKWh: 1.5, BatteryKWh: 0.5, GridKWh: 2}},
+		{name: "rate-limited battery", pue: 1, gen: hourly{2, 0.5, 0, 0},
+			battery: &core.Battery{CapacityKWh: 2, RateKW: 0.25},
+			want:    SiteSupply{LoadKWh: 4, RenewableKWh: 1.5, BatteryKWh: 0.25, GridKWh: 2.25, CurtailedKWh: 0.75}},
+		{name: "full battery curtails", pue: 1, gen: hourly{3, 0, 0, 0},
+			battery: &core.Battery{CapacityKWh: 1},
+			want:    SiteSupply{LoadKWh: 4, RenewableKWh: 1, BatteryKWh: 1, GridKWh: 2, CurtailedKWh: 1}},
+		{name: "initial charge, no generation", pue: 1,
+			battery: &core.Battery{CapacityKWh: 4, InitialSoC: 0.5},
+			want:    SiteSupply{LoadKWh: 4, BatteryKWh: 2, GridKWh: 2}},
+		{name: "PUE scales the load", pue: 2, gen: hourly{2, 0.5, 0, 0},
+			want: SiteSupply{LoadKWh: 8, RenewableKWh: 2.5, GridKWh: 5.5}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			n := core.NewNode("n0", 4, 8, 0)
+			n.Metadata["ci_profile"] = "static:100"
+			n.Power = core.LinearPower{IdleW: 1000, PeakW: 1000}
+			n.Site = &core.Site{ID: "s", PUE: tc.pue, Battery: tc.battery}
+			if tc.gen != nil {
+				n.Site.Renewable = tc.gen
+			}
+			n.SiteID = "s"
+			logs := []core.LogEntry{{JobID: "j", Node: "n0", SiteID: "s", Submit: t0, Start: t0, End: t0.Add(4 * time.Hour), CPU: 1}}
+
+			a := Account(logs, []*core.SimulatedNode{n}, time.Hour)
+
+			got, ok := a.OnSite["s"]
+			if supplied := tc.gen != nil || tc.battery != nil; ok != supplied {
+				t.Fatalf("OnSite has site: %v, want %v", ok, supplied)
+			}
+			if !ok {
+				got = SiteSupply{LoadKWh: a.FacilityKWh, GridKWh: a.GridKWh}
+			}
+			for _, f := range []struct {
+				name      string
+				got, want float64
+			}{
+				{"load", got.LoadKWh, tc.want.LoadKWh},
+				{"renewable", got.RenewableKWh, tc.want.RenewableKWh},
+				{"battery", got.BatteryKWh, tc.want.BatteryKWh},
+				{"grid", got.GridKWh, tc.want.GridKWh},
+				{"curtailed", got.CurtailedKWh, tc.want.CurtailedKWh},
+				{"run grid", a.GridKWh, tc.want.GridKWh},
+				// only grid energy is charged CI
+				{"CO2", a.TotalCO2g(), 100 * tc.want.GridKWh},
+			} {
+				if math.Abs(f.got-f.want) > 1e-9 {
+					t.Errorf("%s = %g, want %g", f.name, f.got, f.want)
+				}
+			}
+			if math.Abs(got.LoadKWh-(got.RenewableKWh+got.BatteryKWh+got.GridKWh)) > 1e-9 {
+				t.Errorf("load %g not met by renewable %g + battery %g + grid %g",
+					got.LoadKWh, got.RenewableKWh, got.BatteryKWh, got.GridKWh)
+			}
+		})
+	}
+}
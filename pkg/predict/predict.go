@@ -0,0 +1,314 @@
+// Package predict estimates job runtimes from the history of completed jobs,
+// so policies can be evaluated without knowing durations up front. Every
+// predictor implements core.RuntimePredictor; BaseSim feeds each completed
+// job back through ObserveRuntime.
+package predict
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"kube-scheduler/pkg/core"
+)
+
+// DefaultRuntime is predicted before any job has completed.
+const DefaultRuntime = 10 * time.Minute
+
+// historyCap bounds the samples kept per tag by Quantile.
+const historyCap = 1000
+
+func tagOf(j core.Job) string { return j.Tags["tag"] }
+
+func seconds(s float64) time.Duration { return time.Duration(s * float64(time.Second)) }
+
+// Oracle returns the true duration (the behaviour without a predictor).
+type Oracle struct{}
+
+func (Oracle) PredictRuntime(j core.Job) time.Duration { return seconds(j.EstimatedDuration) }
+func (Oracle) ObserveRuntime(core.Job, time.Duration)  {}
+
+// Noisy perturbs the true duration by a log-normal factor exp(Sigma·z), so
+// the median prediction is exact and Sigma sets the spread. The factor is
+// fixed per job (from Seed and the job ID), so a job gets the same
+// prediction at every scheduling pass.
+type Noisy struct {
+	Sigma float64
+	Seed  int64
+}
+
+func (p Noisy) PredictRuntime(j core.Job) time.Duration {
+	z := rand.New(rand.NewSource(core.SeedFor(p.Seed, j.ID))).NormFloat64()
+	return seconds(j.EstimatedDuration * math.Exp(p.Sigma*z))
+}
+func (Noisy) ObserveRuntime(core.Job, time.Duration) {}
+
+// TagMean predicts the mean runtime of completed jobs with the same tag,
+// falling back to the mean over all tags and then to Default.
+type TagMean struct {
+	Default time.Duration
+	sum     map[string]float64
+	cnt     map[string]int
+	all     float64
+	n       int
+}
+
+func (p *TagMean) PredictRuntime(j core.Job) time.Duration {
+	if c := p.cnt[tagOf(j)]; c > 0 {
+		return seconds(p.sum[tagOf(j)] / float64(c))
+	}
+	if p.n > 0 {
+		return seconds(p.all / float64(p.n))
+	}
+	return orDefault(p.Default)
+}
+
+func (p *TagMean) ObserveRuntime(j core.Job, actual time.Duration) {
+	if p.sum == nil {
+		p.sum, p.cnt = map[string]float64{}, map[string]int{}
+	}
+	p.sum[tagOf(j)] += actual.Seconds()
+	p.cnt[tagOf(j)]++
+	p.all += actual.Seconds()
+	p.n++
+}
+
+// Quantile predicts the Q-quantile of the last runtimes of jobs with the
+// same tag (all tags until the tag has history), a conservative estimate
+// for Q > 0.5.
+type Quantile struct {
+	Q       float64
+	Default time.Duration
+	byTag   map[string][]float64 // in arrival order
+	all     []float64
+	sorted  map[string][]float64 // sorted copies by tag ("\x00" = all)
+}
+
+const allTags = "\x00"
+
+func (p *Quantile) PredictRuntime(j core.Job) time.Duration {
+	key, h := tagOf(j), p.byTag[tagOf(j)]
+	if len(h) == 0 {
+		key, h = allTags, p.all
+	}
+	if len(h) == 0 {
+		return orDefault(p.Default)
+	}
+	s, ok := p.sorted[key]
+	if !ok {
+		s = append([]float64(nil), h...)
+		sort.Float64s(s)
+		p.sorted[key] = s
+	}
+	pos := p.Q * float64(len(s)-1)
+	lo, hi := int(math.Floor(pos)), int(math.Ceil(pos))
+	return seconds(s[lo] + (pos-float64(lo))*(s[hi]-s[lo]))
+}
+
+func (p *Quantile) ObserveRuntime(j core.Job, actual time.Duration) {
+	if p.byTag == nil {
+		p.byTag, p.sorted = map[string][]float64{}, map[string][]float64{}
+	}
+	p.byTag[tagOf(j)] = appendCapped(p.byTag[tagOf(j)], actual.Seconds())
+	p.all = appendCapped(p.all, actual.Seconds())
+	delete(p.sorted, tagOf(j))
+	delete(p.sorted, allTags)
+}
+
+func appendCapped(h []float64, v float64) []float64 {
+	if len(h) >= historyCap {
+		h = append(h[:0], h[1:]...)
+	}
+	return append(h, v)
+}
+
+// Regression fits log(runtime) ~ 1 + cpu + mem + one-hot(tag) by ridge
+// least squares over the completed jobs, refitting lazily after new
+// observations. Tags not seen yet get the intercept. Until there are more
+// observations than features it predicts their geometric mean, and
+// predictions never leave the observed runtime range.
+type Regression struct {
+	Lambda  float64 // ridge penalty (default 1e-3)
+	Default time.Duration
+
+	tags  map[string]int // tag -> feature index
+	xtx   [][]float64
+	xty   []float64
+	n     int
+	sumY  float64 // Σ log(runtime)
+	minY  float64
+	maxY  float64
+	beta  []float64
+	stale bool
+}
+
+func (p *Regression) features(j core.Job, grow bool) []float64 {
+	if p.tags == nil {
+		p.tags = map[string]int{}
+	}
+	t := tagOf(j)
+	if _, ok := p.tags[t]; !ok && grow {
+		p.tags[t] = 3 + len(p.tags)
+		p.growTo(3 + len(p.tags))
+	}
+	x := make([]float64, 3+len(p.tags))
+	x[0], x[1], x[2] = 1, j.CPUReq, j.MemReq
+	if i, ok := p.tags[t]; ok {
+		x[i] = 1
+	}
+	return x
+}
+
+func (p *Regression) growTo(d int) {
+	for i := range p.xtx {
+		for len(p.xtx[i]) < d {
+			p.xtx[i] = append(p.xtx[i], 0)
+		}
+	}
+	for len(p.xtx) < d {
+		p.xtx = append(p.xtx, make([]float64, d))
+	}
+	for len(p.xty) < d {
+		p.xty = append(p.xty, 0)
+	}
+}
+
+func (p *Regression) ObserveRuntime(j core.Job, actual time.Duration) {
+	if actual <= 0 {
+		return
+	}
+	p.growTo(3)
+	x := p.features(j, true)
+	y := math.Log(actual.Seconds())
+	for a := range x {
+		for b := range x {
+			p.xtx[a][b] += x[a] * x[b]
+		}
+		p.xty[a] += x[a] * y
+	}
+	if p.n == 0 || y < p.minY {
+		p.minY = y
+	}
+	if p.n == 0 || y > p.maxY {
+		p.maxY = y
+	}
+	p.sumY += y
+	p.n++
+	p.stale = true
+}
+
+func (p *Regression) PredictRuntime(j core.Job) time.Duration {
+	if p.n == 0 {
+		return orDefault(p.Default)
+	}
+	if p.n <= len(p.xty) {
+		return seconds(math.Exp(p.sumY / float64(p.n)))
+	}
+	if p.stale {
+		p.beta = p.solve()
+		p.stale = false
+	}
+	x := p.features(j, false)
+	y := 0.0
+	for i := range x {
+		if i < len(p.beta) {
+			y += x[i] * p.beta[i]
+		}
+	}
+	return seconds(math.Exp(math.Max(p.minY, math.Min(p.maxY, y))))
+}
+
+// solve returns (XᵀX + λI)⁻¹ Xᵀy by Gaussian elimination with partial
+// pivoting (the intercept is not penalised).
+func (p *Regression) solve() []float64 {
+	lambda := p.Lambda
+	if lambda <= 0 {
+		lambda = 1e-3
+	}
+	d := len(p.xty)
+	a := make([][]float64, d)
+	for i := range a {
+		a[i] = make([]float64, d+1)
+		copy(a[i], p.xtx[i])
+		if i > 0 {
+			a[i][i] += lambda
+		}
+		a[i][d] = p.xty[i]
+	}
+	for c := 0; c < d; c++ {
+		piv := c
+		for r := c + 1; r < d; r++ {
+			if math.Abs(a[r][c]) > math.Abs(a[piv][c]) {
+				piv = r
+			}
+		}
+		a[c], a[piv] = a[piv], a[c]
+		if math.Abs(a[c][c]) < 1e-12 {
+			continue
+		}
+		for r := 0; r < d; r++ {
+			if r == c {
+				continue
+			}
+			f := a[r][c] / a[c][c]
+			for k := c; k <= d; k++ {
+				a[r][k] -= f * a[c][k]
+			}
+		}
+	}
+	beta := make([]float64, d)
+	for i := range beta {
+		if math.Abs(a[i][i]) >= 1e-12 {
+			beta[i] = a[i][d] / a[i][i]
+		}
+	}
+	return beta
+}
+
+func orDefault(d time.Duration) time.Duration {
+	if d <= 0 {
+		return DefaultRuntime
+	}
+	return d
+}
+
+// Parse builds a predictor from a flag value:
+//
+//	oracle | noisy[:<sigma>] | tag_mean | quantile[:<q>] | regression[:<lambda>]
+//
+// Empty (or "none") returns nil: policies see the true durations. The seed
+// only affects noisy. Predictors keep history, so build one per run.
+func Parse(spec string, seed int64) (core.RuntimePredictor, error) {
+	parts := strings.Split(strings.TrimSpace(spec), ":")
+	arg := func(def float64) (float64, error) {
+		if len(parts) < 2 || parts[1] == "" {
+			return def, nil
+		}
+		return strconv.ParseFloat(parts[1], 64)
+	}
+	switch parts[0] {
+	case "", "none":
+		return nil, nil
+	case "oracle":
+		return Oracle{}, nil
+	case "noisy":
+		sigma, err := arg(0.3)
+		return Noisy{Sigma: sigma, Seed: seed}, err
+	case "tag_mean":
+		return &TagMean{}, nil
+	case "quantile":
+		q, err := arg(0.9)
+		if err == nil && (q < 0 || q > 1) {
+			err = fmt.Errorf("quantile %v outside 0..1", q)
+		}
+		return &Quantile{Q: q}, err
+	case "regression":
+		l, err := arg(1e-3)
+		return &Regression{Lambda: l}, err
+	}
+	return nil, fmt.Errorf("unknown runtime predictor %q", spec)
+}
@@ -0,0 +1,130 @@
+package predict
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"kube-scheduler/pkg/core"
+)
+
+func job(tag string, cpu, mem float64) core.Job {
+	return core.Job{CPUReq: cpu, MemReq: mem, Tags: map[string]string{"tag": tag}}
+}
+
+// obs is a completed job fed to ObserveRuntime.
+type obs struct {
+	j   core.Job
+	run time.Duration
+}
+
+// logLinear is exp(2 + 0.3·cpu + 0.05·mem + 0.7·[tag b]) seconds.
+func logLinear(j core.Job) time.Duration {
+	y := 2 + 0.3*j.CPUReq + 0.05*j.MemReq
+	if tagOf(j) == "b" {
+		y += 0.7
+	}
+	return seconds(math.Exp(y))
+}
+
+func TestPredictors(t *testing.T) {
+	var fit []obs
+	for cpu := 1.0; cpu <= 4; cpu++ {
+		for _, mem := range []float64{2, 8, 32} {
+			for _, tag := range []string{"a", "b"} {
+				j := job(tag, cpu, mem)
+				fit = append(fit, obs{j, logLinear(j)})
+			}
+		}
+	}
+	capped := make([]obs, historyCap+100)
+	for i := range capped {
+		capped[i] = obs{job("a", 1, 1), seconds(float64(i))}
+	}
+	for _, tc := range []struct {
+		name  string
+		p     core.RuntimePredictor
+		seen  []obs
+		query core.Job
+		want  time.Duration
+		tol   float64 // relative
+	}{
+		{"oracle", Oracle{}, nil, core.Job{EstimatedDuration: 90}, 90 * time.Second, 0},
+		{"tag mean", &TagMean{}, []obs{{job("a", 1, 1), 10 * time.Second}, {job("a", 1, 1), 20 * time.Second}, {job("b", 1, 1), time.Hour}},
+			job("a", 1, 1), 15 * time.Second, 0},
+		{"tag mean, unseen tag", &TagMean{}, []obs{{job("a", 1, 1), 10 * time.Second}, {job("b", 1, 1), 20 * time.Second}},
+			job("c", 1, 1), 15 * time.Second, 0},
+		{"quantile default", &Quantile{Q: 0.5, Default: time.Minute}, nil, job("a", 1, 1), time.Minute, 0},
+		{"quantile median, odd", &Quantile{Q: 0.5}, []obs{{job("a", 1, 1), 30 * time.Second}, {job("a", 1, 1), 10 * time.Second}, {job("a", 1, 1), 20 * time.Second}},
+			job("a", 1, 1), 20 * time.Second, 0},
+		{"quantile median, even", &Quantile{Q: 0.5}, []obs{{job("a", 1, 1), 40 * time.Second}, {job("a", 1, 1), 10 * time.Second}, {job("a", 1, 1), 20 * time.Second}, {job("a", 1, 1), 30 * time.Second}},
+			job("a", 1, 1), 25 * time.Second, 0},
+		{"quantile of the tag only", &Quantile{Q: 1}, []obs{{job("a", 1, 1), 10 * time.Second}, {job("b", 1, 1), time.Hour}},
+			job("a", 1, 1), 10 * time.Second, 0},
+		{"quantile, unseen tag uses all tags", &Quantile{Q: 0.5}, []obs{{job("a", 1, 1), 10 * time.Second}, {job("b", 1, 1), 20 * time.Second}, {job("b", 1, 1), 60 * time.Second}},
+			job("c", 1, 1), 20 * time.Second, 0},
+		// only the last historyCap runtimes (100..1099 s) are kept
+		{"quantile history capped", &Quantile{Q: 0.5}, capped, job("a", 1, 1), seconds(599.5), 0},
+		{"regression default", &Regression{}, nil, job("a", 1, 1), DefaultRuntime, 0},
+		{"regression, few observations: geometric mean", &Regression{}, []obs{{job("a", 1, 1), 10 * time.Second}, {job("a", 2, 1), 1000 * time.Second}},
+			job("a", 1, 1), 100 * time.Second, 1e-9},
+		{"regression recovers log-linear runtimes", &Regression{Lambda: 1e-9}, fit, job("b", 3, 8), logLinear(job("b", 3, 8)), 1e-6},
+		{"regression interpolates", &Regression{Lambda: 1e-9}, fit, job("a", 2.5, 16), logLinear(job("a", 2.5, 16)), 1e-6},
+		{"regression clamps to observed range", &Regression{Lambda: 1e-9}, fit, job("b", 40, 32), logLinear(job("b", 4, 32)), 1e-6},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, o := range tc.seen {
+				tc.p.ObserveRuntime(o.j, o.run)
+			}
+			got := tc.p.PredictRuntime(tc.query)
+			if d := math.Abs(got.Seconds() - tc.want.Seconds()); d > tc.tol*tc.want.Seconds()+1e-6 {
+				t.Errorf("predicted %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		spec string
+		want core.RuntimePredictor
+		err  bool
+	}{
+		{"", nil, false},
+		{"none", nil, false},
+		{"oracle", Oracle{}, false},
+		{"noisy", Noisy{Sigma: 0.3, Seed: 7}, false},
+		{"noisy:0.5", Noisy{Sigma: 0.5, Seed: 7}, false},
+		{"quantile", &Quantile{Q: 0.9}, false},
+		{"quantile:0.5", &Quantile{Q: 0.5}, false},
+		{"quantile:1.5", nil, true},
+		{"quantile:-0.1", nil, true},
+		{"quantile:x", nil, true},
+		{"regression:0.1", &Regression{Lambda: 0.1}, false},
+		{"median", nil, true},
+	} {
+		t.Run(tc.spec, func(t *testing.T) {
+			got, err := Parse(tc.spec, 7)
+			if (err != nil) != tc.err {
+				t.Fatalf("Parse(%q) error %v, want error %v", tc.spec, err, tc.err)
+			}
+			if tc.err {
+				return
+			}
+			switch w := tc.want.(type) {
+			case *Quantile:
+				if q, ok := got.(*Quantile); !ok || q.Q != w.Q {
+					t.Errorf("Parse(%q) = %#v, want %#v", tc.spec, got, w)
+				}
+			case *Regression:
+				if r, ok := got.(*Regression); !ok || r.Lambda != w.Lambda {
+					t.Errorf("Parse(%q) = %#v, want %#v", tc.spec, got, w)
+				}
+			default:
+				if got != tc.want {
+					t.Errorf("Parse(%q) = %#v, want %#v", tc.spec, got, tc.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+// Package stats summarises repeated simulation runs: sample mean, standard
+// deviation, Student-t confidence intervals and paired t-tests.
+package stats
+
+import "math"
+
+// Summary describes a sample.
+type Summary struct {
+	N    int
+	Mean float64
+	Std  float64 // sample standard deviation (n-1)
+	CI95 float64 // half-width of the 95% confidence interval of the mean
+}
+
+// Describe summarises xs. With fewer than two values Std and CI95 are NaN.
+func Describe(xs []float64) Summary {
+	s := Summary{N: len(xs), Mean: Mean(xs), Std: math.NaN(), CI95: math.NaN()}
+	if s.N >= 2 {
+		s.Std = Std(xs)
+		s.CI95 = TQuantile(0.975, float64(s.N-1)) * s.Std / math.Sqrt(float64(s.N))
+	}
+	return s
+}
+
+// Mean returns the arithmetic mean (NaN for an empty sample).
+func Mean(xs []float64) float64 {
+	if len(xs) == 0 {
+		return math.NaN()
+	}
+	sum := 0.0
+	for _, x := range xs {
+		sum += x
+	}
+	return sum / float64(len(xs))
+}
+
+// Std returns the sample standard deviation (NaN for fewer than two values).
+func Std(xs []float64) float64 {
+	if len(xs) < 2 {
+		return math.NaN()
+	}
+	m := Mean(xs)
+	ss := 0.0
+	for _, x := range xs {
+		ss += (x - m) * (x - m)
+	}
+	return math.Sqrt(ss / float64(len(xs)-1))
+}
+
+// Paired is the result of a paired t-test of a against b.
+type Paired struct {
+	N        int
+	MeanDiff float64 // mean of a[i]-b[i]
+	CI95     float64 // half-width of the 95% CI of MeanDiff
+	T        float64
+	P        float64 // two-sided p-value
+}
+
+// PairedTTest compares two samples observed on the same units (e.g. two
+// schedulers on the same seeds); extra values of the longer sample are
+// ignored. Differences within rounding error of the values count as zero,
+// so identical samples give T=0, P=1; a constant non-zero difference gives
+// P=0.
+func PairedTTest(a, b []float64) Paired {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	d := make([]float64, n)
+	for i := range d {
+		d[i] = a[i] - b[i]
+		if math.Abs(d[i]) <= 1e-9*math.Max(math.Abs(a[i]), math.Abs(b[i])) {
+			d[i] = 0
+		}
+	}
+	s := Describe(d)
+	r := Paired{N: n, MeanDiff: s.Mean, CI95: s.CI95, T: math.NaN(), P: math.NaN()}
+	if n < 2 {
+		return r
+	}
+	se := s.Std / math.Sqrt(float64(n))
+	switch {
+	case se > 0:
+		r.T = s.Mean / se
+		r.P = 2 * (1 - TCDF(math.Abs(r.T), float64(n-1)))
+	case s.Mean == 0:
+		r.T, r.P = 0, 1
+	default:
+		r.T, r.P = math.Copysign(math.Inf(1), s.Mean), 0
+	}
+	return r
+}
+
+// TCDF is the cumulative distribution function of Student's t with df
+// degrees of freedom.
+func TCDF(t, df float64) float64 {
+	if math.IsInf(t, 1) {
+		return 1
+	}
+	if math.IsInf(t, -1) {
+		return 0
+	}
+	x := df / (df + t*t)
+	tail := 0.5 * RegIncBeta(df/2, 0.5, x)
+	if t > 0 {
+		return 1 - tail
+	}
+	return tail
+}
+
+// TQuantile inverts TCDF by bisection.
+func TQuantile(p, df float64) float64 {
+	if p <= 0 {
+		return math.Inf(-1)
+	}
+	if p >= 1 {
+		return math.Inf(1)
+	}
+	lo, hi := -1.0, 1.0
+	for TCDF(lo, df) > p {
+		lo *= 2
+	}
+	for TCDF(hi, df) < p {
+		hi *= 2
+	}
+	for i := 0; i < 100 && hi-lo > 1e-12; i++ {
+		mid := (lo + hi) / 2
+		if TCDF(mid, df) < p {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+// RegIncBeta is the regularised incomplete beta function I_x(a, b),
+// evaluated with Lentz's continued fraction.
+func RegIncBeta(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log(1-x))
+	// the continued fraction converges fast for x < (a+1)/(a+b+2)
+	if x > (a+1)/(a+b+2) {
+		return 1 - front*betaCF(b, a, 1-x)/b
+	}
+	return front * betaCF(a, b, x) / a
+}
+
+func betaCF(a, b, x float64) float64 {
+	const (
+		eps  = 1e-14
+		tiny = 1e-300
+	)
+	c, d := 1.0, 1-(a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m <= 300; m++ {
+		fm := float64(m)
+		// even step
+		num := fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm))
+		d = 1 + num*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+		// odd step
+		num = -(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1))
+		d = 1 + num*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1) < eps {
+			break
+		}
+	}
+	return h
+}
@@ -0,0 +1,97 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRegIncBeta(t *testing.T) {
+	for _, tc := range []struct {
+		a, b, x, want float64
+	}{
+		{1, 1, 0.3, 0.3},                  // uniform
+		{3, 1, 0.6, 0.216},                // x^a
+		{1, 4, 0.2, 1 - math.Pow(0.8, 4)}, // 1-(1-x)^b
+		{2, 3, 0.3, 0.3483},               // P(Binomial(4, 0.3) >= 2)
+		{2.5, 2.5, 0.5, 0.5},              // symmetric
+		{50, 50, 0.5, 0.5},
+		{2, 3, 0, 0},
+		{2, 3, 1, 1},
+	} {
+		if got := RegIncBeta(tc.a, tc.b, tc.x); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("RegIncBeta(%g, %g, %g) = %.12g, want %g", tc.a, tc.b, tc.x, got, tc.want)
+		}
+	}
+}
+
+func TestTQuantile(t *testing.T) {
+	for _, tc := range []struct {
+		p, df, want, tol float64
+	}{
+		{0.975, 1, math.Tan(0.475 * math.Pi), 1e-9}, // Cauchy: 12.706
+		{0.975, 1, 12.706, 1e-3},
+		{0.975, 2, 4.303, 1e-3},
+		{0.975, 9, 2.262, 1e-3},
+		{0.975, 29, 2.045, 1e-3},
+		{0.95, 5, 2.015, 1e-3},
+		{0.5, 7, 0, 1e-6}, // TCDF is flat to rounding within ~1e-8 of 0
+		{0.025, 9, -2.262, 1e-3},
+	} {
+		if got := TQuantile(tc.p, tc.df); math.Abs(got-tc.want) > tc.tol {
+			t.Errorf("TQuantile(%g, %g) = %.6f, want %g", tc.p, tc.df, got, tc.want)
+		}
+	}
+	if !math.IsInf(TQuantile(1, 3), 1) || !math.IsInf(TQuantile(0, 3), -1) {
+		t.Errorf("TQuantile at 0 and 1 not -Inf and +Inf")
+	}
+}
+
+func TestPairedTTest(t *testing.T) {
+	// t with 2 df has F(t) = 1/2 + t/(2·sqrt(2+t²)); d = 1, 2, 3 gives T = 2·sqrt(3)
+	t3 := 2 * math.Sqrt(3)
+	p3 := 2 * (0.5 - t3/(2*math.Sqrt(2+t3*t3)))
+	nan := math.NaN()
+	for _, tc := range []struct {
+		name  string
+		a, b  []float64
+		n     int
+		mean  float64
+		tstat float64
+		p     float64
+	}{
+		{"identical", []float64{1, 2, 3}, []float64{1, 2, 3}, 3, 0, 0, 1},
+		{"equal within rounding", []float64{0.1 + 0.2, 1}, []float64{0.3, 1}, 2, 0, 0, 1},
+		{"constant non-zero difference", []float64{3, 4, 5}, []float64{1, 2, 3}, 3, 2, math.Inf(1), 0},
+		{"constant negative difference", []float64{1, 2}, []float64{1.5, 2.5}, 2, -0.5, math.Inf(-1), 0},
+		{"varying difference", []float64{2, 4, 6}, []float64{1, 2, 3}, 3, 2, t3, p3},
+		{"longer sample truncated", []float64{2, 4, 6, 100}, []float64{1, 2, 3}, 3, 2, t3, p3},
+		{"single pair", []float64{2}, []float64{1}, 1, 1, nan, nan},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := PairedTTest(tc.a, tc.b)
+			if r.N != tc.n {
+				t.Errorf("N = %d, want %d", r.N, tc.n)
+			}
+			for _, f := range []struct {
+				name      string
+				got, want float64
+			}{
+				{"MeanDiff", r.MeanDiff, tc.mean},
+				{"T", r.T, tc.tstat},
+				{"P", r.P, tc.p},
+			} {
+				if !same(f.got, f.want) {
+					t.Errorf("%s = %g, want %g", f.name, f.got, f.want)
+				}
+			}
+		})
+	}
+}
+
+// same compares floats to 1e-9, with NaN equal to NaN and infinities exact.
+func same(a, b float64) bool {
+	if math.IsNaN(a) || math.IsNaN(b) || math.IsInf(b, 0) {
+		return math.IsNaN(a) == math.IsNaN(b) && (math.IsNaN(a) || a == b)
+	}
+	return math.Abs(a-b) <= 1e-9
+}